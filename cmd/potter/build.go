@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/staka121/potter/internal/analyzer"
 	"github.com/staka121/potter/internal/executor"
@@ -27,6 +29,20 @@ func runBuild(args []string) error {
 
 	promptOnlyFlag := fs.Bool("prompt-only", false, "Generate prompts only (skip AI implementation)")
 	concurrency := fs.Int("concurrency", 0, "Maximum parallel executions (0 = unlimited)")
+	model := fs.String("model", "", "Model to use (overrides potter.yaml)")
+	provider := fs.String("provider", "", "LLM provider: claude or openai (overrides potter.yaml)")
+	baseURL := fs.String("base-url", "", "LLM API base URL, e.g. a proxy or local server (overrides potter.yaml)")
+	scheduleFlag := fs.String("schedule", "", "Scheduling mode: dag or waves (default: dag)")
+	planFile := fs.String("plan", "", "Execute a reviewed plan file from 'potter plan' instead of planning again")
+	estimateFlag := fs.Bool("estimate", false, "Estimate tokens and cost without calling the API")
+	yesFlag := fs.Bool("yes", false, "Skip the cost confirmation prompt")
+	recordDir := fs.String("record", "", "Record every LLM request/response pair as cassettes in this directory")
+	replayDir := fs.String("replay", "", "Serve LLM responses from cassettes in this directory (offline, fails on a miss)")
+	forceFlag := fs.Bool("force", false, "Rebuild every object, ignoring the build cache")
+	forceService := fs.String("force-service", "", "Rebuild the named objects (comma-separated), ignoring the build cache")
+	repairRounds := fs.Int("repair-rounds", 0, "Follow-up turns fixing failed build/vet checks (-1 = none, overrides potter.yaml)")
+	skipChecks := fs.Bool("skip-checks", false, "Don't build and vet the generated code")
+	runTests := fs.Bool("run-tests", false, "Also run the generated tests after build and vet")
 	helpFlag := fs.Bool("help", false, "Show help for build command")
 
 	if err := fs.Parse(args); err != nil {
@@ -38,13 +54,24 @@ func runBuild(args []string) error {
 		return nil
 	}
 
-	// Get tsubo file path
+	// Get tsubo file path (optional with --plan)
 	args = fs.Args()
-	if len(args) == 0 {
-		return fmt.Errorf("tsubo file path required. Usage: potter build <tsubo-file> [options]")
+	tsuboFile := ""
+	if len(args) > 0 {
+		tsuboFile = args[0]
 	}
 
-	tsuboFile := args[0]
+	var plan *types.ImplementationPlan
+	if *planFile != "" {
+		var err error
+		plan, err = loadReviewedPlan(*planFile, tsuboFile)
+		if err != nil {
+			return err
+		}
+		tsuboFile = plan.TsuboFile
+	} else if tsuboFile == "" {
+		return fmt.Errorf("tsubo file path required. Usage: potter build <tsubo-file> [options]")
+	}
 
 	// Verify file exists
 	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
@@ -53,11 +80,41 @@ func runBuild(args []string) error {
 
 	printBuildHeader()
 
-	// Step 1: Parse tsubo file and generate plan
-	fmt.Printf("%s[Step 1] Generating implementation plan%s\n", colorYellow, colorReset)
-	plan, err := generatePlan(tsuboFile)
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if !flagWasSet(fs, "concurrency") {
+		*concurrency = config.Concurrency
+	}
+	llm, err := providerConfig(config, *provider, *baseURL, *model)
+	if err != nil {
+		return err
+	}
+	if *scheduleFlag != "" {
+		config.Schedule = *scheduleFlag
+	}
+	schedule, err := executor.ParseSchedule(config.Schedule)
 	if err != nil {
-		return fmt.Errorf("failed to generate plan: %w", err)
+		return err
+	}
+	applyRepairFlags(fs, config, *repairRounds, *skipChecks, *runTests)
+
+	if plan != nil {
+		fmt.Printf("%s[Step 1] Using reviewed plan: %s%s\n", colorYellow, *planFile, colorReset)
+		fmt.Printf("  %s✓ Contracts unchanged since the plan was generated%s\n", colorGreen, colorReset)
+	} else {
+		// Validate contracts before spending any effort on them
+		if err := validateContracts(tsuboFile); err != nil {
+			return err
+		}
+
+		// Step 1: Parse tsubo file and generate plan
+		fmt.Printf("%s[Step 1] Generating implementation plan%s\n", colorYellow, colorReset)
+		plan, err = generatePlan(tsuboFile, config)
+		if err != nil {
+			return fmt.Errorf("failed to generate plan: %w", err)
+		}
 	}
 
 	fmt.Printf("  %sTsubo: %s%s\n", colorGreen, plan.Tsubo, colorReset)
@@ -70,23 +127,95 @@ func runBuild(args []string) error {
 		return generatePromptsOnly(plan)
 	}
 
-	return executeWithAI(plan, *concurrency)
+	// Objects whose build key is unchanged since their last successful build are skipped
+	cache, err := executor.NewBuildCache(plan, config.Model)
+	if err != nil {
+		return fmt.Errorf("failed to load build cache: %w", err)
+	}
+	if err := cache.SetForce(*forceFlag, splitList(*forceService)); err != nil {
+		return err
+	}
+
+	if *estimateFlag {
+		_, err := printEstimate(plan, config, cache)
+		return err
+	}
+
+	// Replayed builds cost nothing
+	if !*yesFlag && *replayDir == "" {
+		proceed, err := confirmEstimate(plan, config, cache)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			return fmt.Errorf("build aborted: the estimate was not confirmed")
+		}
+	}
+
+	client, err := newImplementer(llm, *recordDir, *replayDir)
+	if err != nil {
+		return err
+	}
+
+	return executeWithAI(plan, *concurrency, llm, client, schedule, cache, executor.NewRepairPolicy(config))
+}
+
+// printEstimate renders every prompt and prints the projected tokens and cost
+func printEstimate(plan *types.ImplementationPlan, config *types.ProjectConfig, cache *executor.BuildCache) (*executor.Estimate, error) {
+	fmt.Printf("%s[Step 2] Estimating tokens and cost (no API calls)%s\n", colorYellow, colorReset)
+	fmt.Println()
+
+	estimate, err := executor.EstimatePlan(plan, config.Model, config.Pricing, cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate plan: %w", err)
+	}
+
+	estimate.Print(os.Stdout)
+	return estimate, nil
 }
 
-func generatePlan(tsuboFile string) (*types.ImplementationPlan, error) {
+// confirmEstimate shows the estimate and asks before spending API credits.
+// Without an answer on stdin (e.g. in CI) it fails instead of assuming one.
+func confirmEstimate(plan *types.ImplementationPlan, config *types.ProjectConfig, cache *executor.BuildCache) (bool, error) {
+	estimate, err := printEstimate(plan, config, cache)
+	if err != nil {
+		return false, err
+	}
+
+	// Nothing to pay for when every object is up to date
+	if estimate.Pending() == 0 {
+		fmt.Println()
+		return true, nil
+	}
+
+	fmt.Print("\nProceed? [y/N]: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, err := reader.ReadString('\n')
+	fmt.Println()
+	if err != nil && (err != io.EOF || strings.TrimSpace(answer) == "") {
+		if err == io.EOF {
+			return false, fmt.Errorf("no answer to the confirmation prompt (stdin is closed or not interactive); pass --yes to build without confirmation")
+		}
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func generatePlan(tsuboFile string, config *types.ProjectConfig) (*types.ImplementationPlan, error) {
 	// Parse tsubo file
 	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
 	if err != nil {
 		return nil, err
 	}
 
-	// Determine directories
 	contractsDir := parser.GetContractsDir(tsuboFile)
-	projectRoot := parser.GetProjectRoot(contractsDir)
 
-	// Implementations directory is in the same directory as the tsubo file
-	tsuboDir := filepath.Dir(tsuboFile)
-	implementationsDir := filepath.Join(tsuboDir, "implementations")
+	// Cross-check dependencies between contracts
+	if err := checkDependencies(tsuboFile, tsuboDef, contractsDir); err != nil {
+		return nil, err
+	}
 
 	// Analyze dependencies
 	objectsWithDeps, err := analyzer.AnalyzeDependencies(tsuboDef, contractsDir)
@@ -94,44 +223,81 @@ func generatePlan(tsuboFile string) (*types.ImplementationPlan, error) {
 		return nil, err
 	}
 
-	// Generate waves
-	waves := planner.GenerateWaves(objectsWithDeps)
+	// Create implementation plan (directories and context files come from potter.yaml)
+	plan, err := planner.GeneratePlan(tsuboDef, tsuboFile, contractsDir, config, objectsWithDeps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record contract hashes so a saved plan can be checked for staleness
+	if err := executor.StampPlan(plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// loadReviewedPlan loads a plan file and refuses it if its contracts changed after it was generated
+func loadReviewedPlan(planFile, tsuboFile string) (*types.ImplementationPlan, error) {
+	plan, err := executor.LoadPlan(planFile)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get context files
-	contextFiles := planner.GetContextFiles(projectRoot)
+	if tsuboFile != "" && !samePath(tsuboFile, plan.TsuboFile) {
+		return nil, fmt.Errorf("plan %s was generated for %s, not %s", planFile, plan.TsuboFile, tsuboFile)
+	}
 
-	// Create implementation plan
-	plan := &types.ImplementationPlan{
-		Tsubo:              tsuboDef.Tsubo.Name,
-		TsuboFile:          tsuboFile,
-		ContractsDir:       contractsDir,
-		ProjectRoot:        projectRoot,
-		ImplementationsDir: implementationsDir,
-		ContextFiles:       contextFiles,
-		Waves:              waves,
+	if err := executor.VerifyPlan(plan); err != nil {
+		return nil, err
 	}
 
 	return plan, nil
 }
 
-func executeWithAI(plan *types.ImplementationPlan, concurrency int) error {
-	fmt.Printf("%s[Step 2] Executing with Claude API%s\n", colorYellow, colorReset)
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+func executeWithAI(plan *types.ImplementationPlan, concurrency int, llm executor.ProviderConfig, client executor.Implementer, schedule executor.Schedule, cache *executor.BuildCache, repair *executor.RepairPolicy) error {
+	fmt.Printf("%s[Step 2] Executing with %s (%s)%s\n", colorYellow, llm.Provider, llm.Model, colorReset)
+	if llm.BaseURL != "" {
+		fmt.Printf("API base URL: %s\n", llm.BaseURL)
+	}
 
 	if concurrency > 0 {
 		fmt.Printf("Concurrency limit: %d\n", concurrency)
 	} else {
-		fmt.Println("Concurrency: unlimited (wave-based parallelism)")
+		fmt.Println("Concurrency: unlimited")
 	}
+	if schedule == executor.ScheduleWaves {
+		fmt.Println("Schedule: waves (each wave waits for the previous one)")
+	} else {
+		fmt.Println("Schedule: dag (each object starts when its dependencies finish)")
+	}
+	printRepairPolicy(repair)
 
-	fmt.Printf("%sWARNING: This will use Claude API credits%s\n", colorYellow, colorReset)
+	if _, replaying := client.(*executor.Replayer); !replaying {
+		fmt.Printf("%sWARNING: This will use API credits%s\n", colorYellow, colorReset)
+	}
 	fmt.Println()
 
 	// Create runner
-	runner, err := executor.NewRunner(plan)
+	runner, err := executor.NewRunner(plan, client)
 	if err != nil {
 		return fmt.Errorf("failed to create runner: %w", err)
 	}
 
+	runner.SetSchedule(schedule)
+	runner.SetBuildCache(cache)
+	runner.SetRepair(repair)
+
 	fmt.Printf("Temporary files will be saved to: %s\n", runner.GetTempDir())
 	fmt.Println()
 
@@ -159,12 +325,9 @@ func generatePromptsOnly(plan *types.ImplementationPlan) error {
 	fmt.Printf("%s[Step 2] Generating implementation prompts%s\n", colorYellow, colorReset)
 	generator := executor.NewPromptGenerator(plan)
 
-	// Create timestamped temp directory
-	// Format: /tmp/potter/{app-name}/yyyymmddhhmmss
-	timestamp := time.Now().Format("20060102150405")
-	tempDir := filepath.Join("/tmp", "potter", plan.Tsubo, timestamp)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+	tempDir, err := executor.NewRunDir(plan)
+	if err != nil {
+		return err
 	}
 
 	for _, wave := range plan.Waves {
@@ -223,13 +386,33 @@ func printBuildUsage() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --prompt-only         Generate prompts only (skip AI implementation)")
-	fmt.Println("  --concurrency N       Maximum parallel executions (default: unlimited)")
+	fmt.Println("  --plan FILE           Execute a plan from 'potter plan' (rejected if contracts changed)")
+	fmt.Println("  --concurrency N       Maximum parallel executions (default: potter.yaml, else unlimited)")
+	fmt.Println("  --model NAME          Model to use (default: potter.yaml, else the provider's default)")
+	fmt.Println("  --provider NAME       LLM provider: claude (default) or openai (OpenAI-compatible APIs)")
+	fmt.Println("  --base-url URL        LLM API base URL, e.g. a proxy or http://localhost:11434/v1")
+	fmt.Println("  --schedule MODE       dag: start each object when its dependencies finish (default)")
+	fmt.Println("                        waves: run waves as barriers")
+	fmt.Println("  --estimate            Print estimated tokens and cost per object and wave, then exit")
+	fmt.Println("  --yes                 Skip the cost confirmation shown before calling the API")
+	fmt.Println("  --record DIR          Save every LLM request/response pair as a cassette in DIR")
+	fmt.Println("  --replay DIR          Serve responses from the cassettes in DIR (no network, fails on a miss)")
+	fmt.Println("  --force               Rebuild every object even if its build key is unchanged")
+	fmt.Println("  --force-service NAMES Rebuild the named objects (comma-separated) even if up to date")
+	fmt.Println("  --repair-rounds N     Follow-up turns fixing failed build/vet checks (default: potter.yaml, else 2; -1 = none)")
+	fmt.Println("  --skip-checks         Don't run go build / go vet on the generated code")
+	fmt.Println("  --run-tests           Also run go test on the generated code")
 	fmt.Println("  --help                Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  potter build app.tsubo.yaml                    # AI-driven implementation")
 	fmt.Println("  potter build --concurrency 4 app.tsubo.yaml    # Limit parallel execution")
 	fmt.Println("  potter build --prompt-only app.tsubo.yaml      # Generate prompts only")
+	fmt.Println("  potter build --estimate app.tsubo.yaml         # Estimate tokens and cost")
+	fmt.Println("  potter build --yes app.tsubo.yaml              # Build without confirmation")
+	fmt.Println("  potter build --force-service user-service app.tsubo.yaml  # Rebuild one object")
+	fmt.Println("  potter build --plan plan.json                  # Execute a reviewed plan")
+	fmt.Println("  potter build --replay testdata/cassettes app.tsubo.yaml  # Offline, deterministic build")
 }
 
 func printBuildSummary(plan *types.ImplementationPlan) {
@@ -243,7 +426,7 @@ func printBuildSummary(plan *types.ImplementationPlan) {
 	fmt.Println()
 
 	fmt.Printf("%sNext steps:%s\n", colorGreen, colorReset)
-	fmt.Println("1. Review the generated prompts in /tmp/tsubo-prompt-*.md")
+	fmt.Println("1. Review the generated tsubo-prompt-*.md files")
 	fmt.Println("2. Execute with AI:")
 	fmt.Println("   potter build <tsubo-file>")
 	fmt.Println()
@@ -256,3 +439,14 @@ func countObjects(plan *types.ImplementationPlan) int {
 	}
 	return count
 }
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/staka121/potter/internal/analyzer"
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/types"
+)
+
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "Output diagnostics as JSON")
+	helpFlag := fs.Bool("help", false, "Show help for check command")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printCheckUsage()
+		return nil
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("tsubo file path required. Usage: potter check <tsubo-file>")
+	}
+
+	tsuboFile := args[0]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse tsubo file: %w", err)
+	}
+
+	diags, err := analyzer.CheckDependencies(tsuboDef, parser.GetContractsDir(tsuboFile))
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		data, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize diagnostics: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDiagnostics(diags)
+	}
+
+	if analyzer.HasErrors(diags) {
+		return fmt.Errorf("dependency check failed: %d error(s), %d warning(s)", analyzer.ErrorCount(diags), analyzer.WarningCount(diags))
+	}
+
+	if !*jsonFlag {
+		if warnings := analyzer.WarningCount(diags); warnings > 0 {
+			fmt.Printf("%s✓ No dependency errors in %s (%d warning(s))%s\n", colorGreen, filepath.Base(tsuboFile), warnings, colorReset)
+		} else {
+			fmt.Printf("%s✓ All dependencies in %s are consistent%s\n", colorGreen, filepath.Base(tsuboFile), colorReset)
+		}
+	}
+	return nil
+}
+
+// checkDependencies runs the dependency consistency check before a command runs.
+// Warnings are printed; errors abort the command.
+func checkDependencies(tsuboFile string, tsuboDef *types.TsuboDefinition, contractsDir string) error {
+	diags, err := analyzer.CheckDependencies(tsuboDef, contractsDir)
+	if err != nil {
+		return fmt.Errorf("failed to check dependencies: %w", err)
+	}
+
+	printDiagnostics(diags)
+
+	if analyzer.HasErrors(diags) {
+		return fmt.Errorf("dependency check failed with %d error(s). Run 'potter check %s' for details", analyzer.ErrorCount(diags), tsuboFile)
+	}
+	return nil
+}
+
+// printDiagnostics prints each diagnostic on its own line
+func printDiagnostics(diags []analyzer.Diagnostic) {
+	for _, d := range diags {
+		color := colorYellow
+		if d.Severity == analyzer.SeverityError {
+			color = colorRed
+		}
+		fmt.Printf("%s%s%s\n", color, d, colorReset)
+	}
+}
+
+func printCheckUsage() {
+	fmt.Println("Usage: potter check [options] <tsubo-file>")
+	fmt.Println()
+	fmt.Println("Cross-checks dependencies declared in the tsubo file and object contracts.")
+	fmt.Println("Exits non-zero on errors; warnings are reported but do not fail the check.")
+	fmt.Println()
+	fmt.Println("Checks:")
+	fmt.Println("  - Every dependency target is an object in the tsubo")
+	fmt.Println("  - Every endpoint in dependencies.services[].endpoints exists in the target contract")
+	fmt.Println("    (endpoint ID, \"METHOD /path\" or \"/path\")")
+	fmt.Println("  - Tsubo object dependencies match the contract's dependencies.services")
+	fmt.Println("    (warning only: the contract is authoritative)")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --json            Output diagnostics as JSON")
+	fmt.Println("  --help            Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter check ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+	fmt.Println("  potter check --json ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/staka121/potter/internal/executor"
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// loadProjectConfig loads potter.yaml for a tsubo file (or directory) and reports where it came from
+func loadProjectConfig(path string) (*types.ProjectConfig, error) {
+	config, err := parser.LoadProjectConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if config.Path != "" {
+		fmt.Printf("Using project config: %s\n", config.Path)
+	}
+	return config, nil
+}
+
+// providerConfig resolves the LLM provider from potter.yaml and command-line
+// overrides, filling in the provider's default model
+func providerConfig(config *types.ProjectConfig, provider, baseURL, model string) (executor.ProviderConfig, error) {
+	if provider != "" {
+		config.Provider = provider
+	}
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	if model != "" {
+		config.Model = model
+	}
+
+	p, err := executor.ParseProvider(config.Provider)
+	if err != nil {
+		return executor.ProviderConfig{}, err
+	}
+	if config.Model == "" {
+		config.Model = p.DefaultModel()
+	}
+
+	return executor.ProviderConfig{Provider: p, BaseURL: config.BaseURL, Model: config.Model, MaxAttempts: config.MaxAttempts}, nil
+}
+
+// newImplementer creates the LLM client. With record, every call is also saved
+// as a cassette; with replay, recorded cassettes are served instead of calling
+// the provider (no network access, no API key needed).
+func newImplementer(llm executor.ProviderConfig, record, replay string) (executor.Implementer, error) {
+	if record != "" && replay != "" {
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	}
+
+	if replay != "" {
+		replayer, err := executor.NewReplayer(replay, llm.Model, llm.Provider.WritesFiles())
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Replaying recorded LLM calls from %s (no API calls)\n", replay)
+		return replayer, nil
+	}
+
+	client, err := executor.NewImplementer(llm)
+	if err != nil {
+		return nil, err
+	}
+
+	if record != "" {
+		recorder, err := executor.NewRecorder(client, record)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Recording LLM calls to %s\n", record)
+		return recorder, nil
+	}
+
+	return client, nil
+}
+
+// applyRepairFlags overrides the potter.yaml check and repair settings with
+// the command-line flags that were set
+func applyRepairFlags(fs *flag.FlagSet, config *types.ProjectConfig, repairRounds int, skipChecks, runTests bool) {
+	if flagWasSet(fs, "repair-rounds") {
+		config.RepairRounds = repairRounds
+	}
+	if flagWasSet(fs, "skip-checks") {
+		config.SkipChecks = skipChecks
+	}
+	if flagWasSet(fs, "run-tests") {
+		config.RunTests = runTests
+	}
+}
+
+// printRepairPolicy reports how generated code will be checked
+func printRepairPolicy(repair *executor.RepairPolicy) {
+	if repair == nil {
+		fmt.Println("Checks: skipped")
+		return
+	}
+	fmt.Printf("Checks: %s (up to %d repair round(s))\n", repair.CheckList(), repair.Rounds)
+}
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/database"
 	"github.com/staka121/potter/pkg/k8s"
+	"github.com/staka121/potter/pkg/state"
 )
 
 func runDeploy(args []string) error {
@@ -44,9 +46,10 @@ func runDeployGenerate(args []string) error {
 	registry := fs.String("registry", "", "Docker image registry (e.g., docker.io/myorg)")
 	imageTag := fs.String("tag", "latest", "Docker image tag")
 	replicas := fs.Int("replicas", 1, "Default number of replicas")
-	ingressEnabled := fs.Bool("ingress", true, "Enable Ingress generation (replaces gateway-service)")
+	ingressEnabled := fs.Bool("ingress", true, "Enable Ingress generation (replaces the gateway)")
 	ingressHost := fs.String("ingress-host", "", "Ingress host (e.g., todo.example.com)")
 	ingressClass := fs.String("ingress-class", "nginx", "Ingress class name")
+	externalDB := fs.Bool("external-db", false, "Reference existing database Secrets instead of generating StatefulSets")
 	helpFlag := fs.Bool("help", false, "Show help for deploy generate command")
 
 	if err := fs.Parse(args); err != nil {
@@ -73,6 +76,17 @@ func runDeployGenerate(args []string) error {
 
 	printDeployGenerateHeader()
 
+	projectConfig, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if !flagWasSet(fs, "namespace") && projectConfig.Namespace != "" {
+		*namespace = projectConfig.Namespace
+	}
+	if !flagWasSet(fs, "registry") {
+		*registry = projectConfig.Registry
+	}
+
 	// Step 1: Parse tsubo file
 	fmt.Printf("%s[Step 1] Parsing Tsubo definition%s\n", colorYellow, colorReset)
 	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
@@ -87,22 +101,49 @@ func runDeployGenerate(args []string) error {
 	// Step 2: Generate Kubernetes manifests
 	fmt.Printf("%s[Step 2] Generating Kubernetes manifests%s\n", colorYellow, colorReset)
 
-	// Configure Ingress
+	// Configure Ingress (routes follow the tsubo's gateway section)
 	ingressConfig := &k8s.IngressConfig{
 		Enabled:      *ingressEnabled,
 		Host:         *ingressHost,
 		TLSEnabled:   false,
 		IngressClass: *ingressClass,
 		Annotations:  make(map[string]string),
+		Routes:       parser.GatewayRoutes(tsuboDef, parser.GetContractsDir(tsuboFile)),
+	}
+	if ingressConfig.Enabled && !parser.GatewayEnabled(tsuboDef) {
+		fmt.Printf("  %sGateway disabled in the tsubo file: skipping Ingress%s\n", colorYellow, colorReset)
+		ingressConfig.Enabled = false
+	}
+	if ingressConfig.Enabled && tsuboDef.Gateway.Contract != "" {
+		fmt.Printf("  %s⚠ Gateway endpoints from %s are not served by the Ingress%s\n", colorYellow, tsuboDef.Gateway.Contract, colorReset)
+	}
+
+	// Databases declared in the contracts: generated in-cluster, or provided as Secrets
+	databases, err := database.ForTsubo(tsuboDef, parser.GetContractsDir(tsuboFile))
+	if err != nil {
+		return err
+	}
+	if !*externalDB {
+		mgr := state.NewManager(tsuboFile)
+		for _, instances := range databases {
+			err := database.AssignCredentials(instances, func(key string) (string, error) {
+				return mgr.Secret("k8s/" + *namespace + "/" + key)
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	config := &k8s.GeneratorConfig{
-		Namespace:       *namespace,
-		OutputDir:       *outputDir,
-		ImageRegistry:   *registry,
-		ImageTag:        *imageTag,
-		DefaultReplicas: int32(*replicas),
-		Ingress:         ingressConfig,
+		Namespace:         *namespace,
+		OutputDir:         *outputDir,
+		ImageRegistry:     *registry,
+		ImageTag:          *imageTag,
+		DefaultReplicas:   int32(*replicas),
+		Ingress:           ingressConfig,
+		Databases:         databases,
+		ExternalDatabases: *externalDB,
 	}
 
 	generator := k8s.NewGenerator(config)
@@ -116,9 +157,24 @@ func runDeployGenerate(args []string) error {
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Printf("  1. Review manifests in: %s\n", *outputDir)
-	fmt.Printf("  2. Apply to cluster: kubectl apply -f %s/\n", *outputDir)
+	if len(manifests.DatabaseSecrets) > 0 {
+		fmt.Printf("  2. Apply to cluster: kubectl apply -f %s/ && kubectl apply -f %s/\n", filepath.Join(*outputDir, k8s.SecretsDir), *outputDir)
+	} else {
+		fmt.Printf("  2. Apply to cluster: kubectl apply -f %s/\n", *outputDir)
+	}
 	fmt.Printf("  3. Check status: kubectl get pods -n %s\n", manifests.Namespace)
 
+	if *externalDB && len(databases) > 0 {
+		fmt.Println()
+		fmt.Printf("%sExternal databases: create these Secrets (key \"url\") before applying:%s\n", colorYellow, colorReset)
+		for _, objRef := range tsuboDef.Objects {
+			for _, inst := range databases[objRef.Name] {
+				fmt.Printf("  kubectl create secret generic %s -n %s --from-literal=url=<%s connection string>\n",
+					inst.Host, manifests.Namespace, inst.Type)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -149,14 +205,16 @@ func printDeployGenerateUsage() {
 	fmt.Println("  potter deploy generate [options] <tsubo-file>")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --namespace string      Kubernetes namespace (default: default)")
+	fmt.Println("  --namespace string      Kubernetes namespace (default: potter.yaml, else default)")
 	fmt.Println("  --output string         Output directory for manifests (default: k8s)")
-	fmt.Println("  --registry string       Docker image registry (e.g., docker.io/myorg)")
+	fmt.Println("  --registry string       Docker image registry (default: potter.yaml, e.g., docker.io/myorg)")
 	fmt.Println("  --tag string            Docker image tag (default: latest)")
 	fmt.Println("  --replicas int          Default number of replicas (default: 1)")
 	fmt.Println("  --ingress               Enable Ingress generation (default: true)")
 	fmt.Println("  --ingress-host string   Ingress host (e.g., todo.example.com)")
 	fmt.Println("  --ingress-class string  Ingress class name (default: nginx)")
+	fmt.Println("  --external-db           Use existing Secrets <service>-<database> (key url) for")
+	fmt.Println("                          postgres/mysql/redis instead of generating StatefulSets")
 	fmt.Println("  --help                  Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -196,6 +254,15 @@ func runDeployApply(args []string) error {
 
 	printDeployApplyHeader()
 
+	// Fall back to the namespace from potter.yaml (searched from the current directory)
+	if *namespace == "" {
+		projectConfig, err := loadProjectConfig(".")
+		if err != nil {
+			return err
+		}
+		*namespace = projectConfig.Namespace
+	}
+
 	// Step 1: Check if kubectl is available
 	fmt.Printf("%s[Step 1] Checking kubectl availability%s\n", colorYellow, colorReset)
 	if err := checkKubectlAvailable(); err != nil {
@@ -225,17 +292,26 @@ func runDeployApply(args []string) error {
 	// Step 3: Apply manifests
 	fmt.Printf("%s[Step 3] Applying manifests to cluster%s\n", colorYellow, colorReset)
 
-	applyArgs := []string{"apply", "-f", *manifestDir}
-	if *namespace != "" {
-		applyArgs = append(applyArgs, "-n", *namespace)
+	// Generated Secrets live in a subdirectory that kubectl does not descend
+	// into; apply them before the workloads that reference them
+	dirs := []string{*manifestDir}
+	secretsDir := filepath.Join(*manifestDir, k8s.SecretsDir)
+	if info, err := os.Stat(secretsDir); err == nil && info.IsDir() {
+		dirs = append([]string{secretsDir}, dirs...)
 	}
+	for _, dir := range dirs {
+		applyArgs := []string{"apply", "-f", dir}
+		if *namespace != "" {
+			applyArgs = append(applyArgs, "-n", *namespace)
+		}
 
-	cmd := exec.Command("kubectl", applyArgs...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+		cmd := exec.Command("kubectl", applyArgs...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to apply manifests: %w", err)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to apply manifests: %w", err)
+		}
 	}
 	fmt.Println()
 
@@ -365,7 +441,7 @@ func printDeployApplyUsage() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --manifests string   Directory containing K8s manifests (default: k8s)")
-	fmt.Println("  --namespace string   Kubernetes namespace (overrides manifest namespace)")
+	fmt.Println("  --namespace string   Kubernetes namespace (overrides manifest namespace; default: potter.yaml)")
 	fmt.Println("  --wait               Wait for rollout to complete (default: true)")
 	fmt.Println("  --timeout duration   Timeout for rollout (default: 5m)")
 	fmt.Println("  --help               Show this help message")
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/openapi"
+	"github.com/staka121/potter/pkg/types"
+)
+
+func runExport(args []string) error {
+	if len(args) == 0 {
+		printExportUsage()
+		return nil
+	}
+
+	subcommand := args[0]
+
+	switch subcommand {
+	case "openapi":
+		return runExportOpenAPI(args[1:])
+	case "help", "--help", "-h":
+		printExportUsage()
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown export subcommand: %s\n\n", subcommand)
+		printExportUsage()
+		return fmt.Errorf("unknown export subcommand: %s", subcommand)
+	}
+}
+
+func runExportOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("export openapi", flag.ExitOnError)
+	outputDir := fs.String("output", "", "Output directory (default: <tsubo-dir>/openapi)")
+	format := fs.String("format", "yaml", "Output format: yaml or json")
+	helpFlag := fs.Bool("help", false, "Show help for export openapi command")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printExportUsage()
+		return nil
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("tsubo file path required. Usage: potter export openapi [options] <tsubo-file>")
+	}
+
+	tsuboFile := args[0]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	if *format != "yaml" && *format != "json" {
+		return fmt.Errorf("unsupported format %q (expected yaml or json)", *format)
+	}
+
+	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse tsubo file: %w", err)
+	}
+
+	outDir := *outputDir
+	if outDir == "" {
+		outDir = filepath.Join(filepath.Dir(tsuboFile), "openapi")
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	contractsDir := parser.GetContractsDir(tsuboFile)
+	objects := make(map[string]*types.ObjectDefinition)
+
+	for _, objRef := range tsuboDef.Objects {
+		objectDef, err := parser.ParseObjectFile(filepath.Join(contractsDir, objRef.Contract))
+		if err != nil {
+			return fmt.Errorf("failed to parse contract for %s: %w", objRef.Name, err)
+		}
+		objects[objRef.Name] = objectDef
+
+		doc, err := openapi.FromObject(objectDef, openapi.ServiceURL(objRef))
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", objRef.Name, err)
+		}
+
+		path := filepath.Join(outDir, fmt.Sprintf("%s.openapi.%s", objRef.Name, *format))
+		if err := writeOpenAPI(path, doc, *format); err != nil {
+			return err
+		}
+		fmt.Printf("  %s✓%s %s\n", colorGreen, colorReset, path)
+	}
+
+	// Without a gateway, each service is called on its own port
+	gatewayURL := ""
+	label := "services"
+	if parser.GatewayEnabled(tsuboDef) {
+		gatewayURL = fmt.Sprintf("http://localhost:%d", tsuboDef.Gateway.Port)
+		label = "gateway"
+
+		// Internal-only services are not reachable through the gateway
+		for _, route := range tsuboDef.Gateway.Routes {
+			if route.Exclude {
+				delete(objects, route.Service)
+			}
+		}
+	}
+
+	merged, err := openapi.FromTsubo(tsuboDef, objects, gatewayURL)
+	if err != nil {
+		return fmt.Errorf("failed to build merged document: %w", err)
+	}
+
+	path := filepath.Join(outDir, fmt.Sprintf("%s.openapi.%s", tsuboDef.Tsubo.Name, *format))
+	if err := writeOpenAPI(path, merged, *format); err != nil {
+		return err
+	}
+	fmt.Printf("  %s✓%s %s (%s)\n", colorGreen, colorReset, path, label)
+
+	fmt.Println()
+	fmt.Printf("%s✓ Exported %d service(s) to OpenAPI %s%s\n", colorGreen, len(tsuboDef.Objects), openapi.Version, colorReset)
+	return nil
+}
+
+func writeOpenAPI(path string, doc *openapi.Document, format string) error {
+	data, err := openapi.Marshal(doc, format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func printExportUsage() {
+	fmt.Println("Usage: potter export <subcommand> [options] <tsubo-file>")
+	fmt.Println()
+	fmt.Println("Subcommands:")
+	fmt.Println("  openapi    Export contracts as OpenAPI 3.1 documents")
+	fmt.Println()
+	fmt.Println("Writes one document per object (<service>.openapi.yaml) and a merged")
+	fmt.Println("document for the whole tsubo as seen through the gateway (<tsubo>.openapi.yaml).")
+	fmt.Println("If the gateway is disabled, each operation of the merged document is served")
+	fmt.Println("by its own service on the service's port.")
+	fmt.Println()
+	fmt.Println("Options (openapi):")
+	fmt.Println("  --output DIR      Output directory (default: <tsubo-dir>/openapi)")
+	fmt.Println("  --format FORMAT   yaml or json (default: yaml)")
+	fmt.Println("  --help            Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter export openapi ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+	fmt.Println("  potter export openapi --format json --output ./api ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+}
@@ -0,0 +1,32 @@
+package main
+
+import "flag"
+
+// flagWasSet reports whether a flag was given explicitly on the command line
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments (e.g. "potter graph app.tsubo.yaml --format dot") and returns the
+// positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) > 0 {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+	}
+	return positional, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/graph"
+)
+
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := fs.String("format", "dot", "Output format: dot, mermaid or json")
+	highlight := fs.String("highlight", "", "Highlight a service (or database) and everything that depends on it")
+	output := fs.String("output", "", "Write the graph to a file instead of stdout")
+	helpFlag := fs.Bool("help", false, "Show help for graph command")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printGraphUsage()
+		return nil
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("tsubo file path required. Usage: potter graph <tsubo-file> [--format dot|mermaid|json]")
+	}
+
+	tsuboFile := positional[0]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse tsubo file: %w", err)
+	}
+
+	g, err := graph.Build(tsuboDef, parser.GetContractsDir(tsuboFile))
+	if err != nil {
+		return fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	if *highlight != "" {
+		if err := g.HighlightDependents(*highlight); err != nil {
+			return err
+		}
+	}
+
+	data, err := graph.Render(g, *format)
+	if err != nil {
+		return err
+	}
+
+	// Diagnostics go to stderr so stdout can be piped into dot/mmdc
+	for _, cycle := range g.Cycles {
+		fmt.Fprintf(os.Stderr, "%s⚠ circular dependency: %s%s\n", colorYellow, strings.Join(cycle, " → "), colorReset)
+	}
+	if *highlight != "" {
+		dependents := g.Dependents(g.Highlight)
+		fmt.Fprintf(os.Stderr, "%s%s is used by %d object(s): %v%s\n", colorYellow, *highlight, len(dependents), dependents, colorReset)
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	fmt.Printf("%s✓ Graph written to %s%s\n", colorGreen, *output, colorReset)
+	return nil
+}
+
+func printGraphUsage() {
+	fmt.Println("Usage: potter graph <tsubo-file> [options]")
+	fmt.Println()
+	fmt.Println("Renders the tsubo dependency graph: services, the auto-generated gateway,")
+	fmt.Println("databases and external services, with the endpoints (or tables) each")
+	fmt.Println("dependency uses as edge labels and implementation waves as clusters.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --format FORMAT    dot, mermaid or json (default: dot)")
+	fmt.Println("  --highlight NAME   Highlight NAME and everything that depends on it")
+	fmt.Println("  --output FILE      Write to FILE instead of stdout")
+	fmt.Println("  --help             Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter graph app.tsubo.yaml | dot -Tsvg > graph.svg")
+	fmt.Println("  potter graph app.tsubo.yaml --format mermaid --output graph.mmd")
+	fmt.Println("  potter graph app.tsubo.yaml --highlight user-service")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/impact"
+)
+
+func runImpact(args []string) error {
+	fs := flag.NewFlagSet("impact", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "", "Narrow the analysis to one endpoint (ID, \"METHOD /path\" or path)")
+	namespace := fs.String("namespace", "default", "Kubernetes namespace used for env var values")
+	jsonFlag := fs.Bool("json", false, "Output the report as JSON")
+	helpFlag := fs.Bool("help", false, "Show help for impact command")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printImpactUsage()
+		return nil
+	}
+
+	if len(positional) < 2 {
+		return fmt.Errorf("tsubo file and service required. Usage: potter impact <tsubo-file> <service> [--endpoint id]")
+	}
+
+	tsuboFile, service := positional[0], positional[1]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	// Loaded quietly: stdout may carry the JSON report
+	projectConfig, err := parser.LoadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if !flagWasSet(fs, "namespace") && projectConfig.Namespace != "" {
+		*namespace = projectConfig.Namespace
+	}
+
+	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse tsubo file: %w", err)
+	}
+
+	report, err := impact.Analyze(tsuboDef, parser.GetContractsDir(tsuboFile), service, *endpoint, *namespace)
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize impact report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printImpactReport(report)
+	return nil
+}
+
+func printImpactReport(report *impact.Report) {
+	target := report.Service
+	if report.Endpoint != nil {
+		target = fmt.Sprintf("%s %s %s (%s)", report.Service, report.Endpoint.Method, report.Endpoint.Path, report.Endpoint.ID)
+	}
+	fmt.Printf("%sImpact of changing %s%s\n", colorBlue, target, colorReset)
+	fmt.Println()
+
+	fmt.Printf("%sDependents (%d):%s\n", colorYellow, len(report.Dependents), colorReset)
+	if len(report.Dependents) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, dep := range report.Dependents {
+		kind := ""
+		if dep.Kind != "service" {
+			kind = " [" + dep.Kind + "]"
+		}
+		switch {
+		case dep.Undeclared:
+			fmt.Printf("  %s%s%s%s  direct, endpoints not declared (possibly affected)\n", colorRed, dep.Name, kind, colorReset)
+		case dep.Direct && len(dep.Endpoints) > 0:
+			fmt.Printf("  %s%s%s%s  direct, uses %s\n", colorRed, dep.Name, kind, colorReset, strings.Join(dep.Endpoints, ", "))
+		case dep.Direct:
+			fmt.Printf("  %s%s%s%s  direct\n", colorRed, dep.Name, kind, colorReset)
+		default:
+			fmt.Printf("  %s%s  transitive (depth %d, via %s)\n", dep.Name, kind, dep.Depth, dep.Via)
+		}
+	}
+	fmt.Println()
+
+	fmt.Printf("%sGateway / Ingress routes (%d):%s\n", colorYellow, len(report.Routes), colorReset)
+	if len(report.Routes) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, route := range report.Routes {
+		fmt.Printf("  %-30s → %s\n", route.Path, route.Service)
+	}
+	fmt.Println()
+
+	fmt.Printf("%sMonitoring rules (%d):%s\n", colorYellow, len(report.Monitoring), colorReset)
+	if len(report.Monitoring) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, rule := range report.Monitoring {
+		if len(rule.Alerts) > 0 {
+			fmt.Printf("  %s %s (%s)\n", rule.Kind, rule.Name, strings.Join(rule.Alerts, ", "))
+		} else {
+			fmt.Printf("  %s %s\n", rule.Kind, rule.Name)
+		}
+	}
+	fmt.Println()
+
+	fmt.Printf("%sKubernetes env vars (%d):%s\n", colorYellow, len(report.EnvVars), colorReset)
+	if len(report.EnvVars) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, env := range report.EnvVars {
+		fmt.Printf("  %s: %s=%s\n", env.Object, env.Name, env.Value)
+	}
+}
+
+func printImpactUsage() {
+	fmt.Println("Usage: potter impact <tsubo-file> <service> [options]")
+	fmt.Println()
+	fmt.Println("Lists everything affected by a change to a service's contract: its direct")
+	fmt.Println("and transitive dependents, the gateway / Ingress routes serving them, their")
+	fmt.Println("monitoring rules and the Kubernetes env vars that point at the service.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --endpoint REF     Only count direct dependents whose declared endpoints")
+	fmt.Println("                     reference REF (endpoint ID, \"METHOD /path\" or path)")
+	fmt.Println("  --namespace NS     Kubernetes namespace for env var values (default: potter.yaml, else default)")
+	fmt.Println("  --json             Output the report as JSON")
+	fmt.Println("  --help             Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter impact app.tsubo.yaml user-service")
+	fmt.Println("  potter impact app.tsubo.yaml user-service --endpoint validate_user")
+	fmt.Println("  potter impact app.tsubo.yaml user-service --json")
+}
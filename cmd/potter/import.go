@@ -0,0 +1,323 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/openapi"
+	"github.com/staka121/potter/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+func runImport(args []string) error {
+	if len(args) == 0 {
+		printImportUsage()
+		return nil
+	}
+
+	subcommand := args[0]
+
+	switch subcommand {
+	case "openapi":
+		return runImportOpenAPI(args[1:])
+	case "help", "--help", "-h":
+		printImportUsage()
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown import subcommand: %s\n\n", subcommand)
+		printImportUsage()
+		return fmt.Errorf("unknown import subcommand: %s", subcommand)
+	}
+}
+
+func runImportOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("import openapi", flag.ExitOnError)
+	serviceFlag := fs.String("service", "", "Service name (required)")
+	tsuboFlag := fs.String("tsubo", "", "Tsubo file to add the service to")
+	outputFlag := fs.String("output", "", "Output contract file (default: <service>.object.yaml next to the tsubo file)")
+	portFlag := fs.Int("port", 0, "Runtime port for the tsubo entry (default: next free port)")
+	forceFlag := fs.Bool("force", false, "Overwrite an existing contract file")
+	helpFlag := fs.Bool("help", false, "Show help for import openapi command")
+
+	// Allow flags after the spec path
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printImportUsage()
+		return nil
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("OpenAPI spec path required. Usage: potter import openapi <spec> --service <name>")
+	}
+	if *serviceFlag == "" {
+		return fmt.Errorf("--service is required. Usage: potter import openapi <spec> --service <name>")
+	}
+
+	specFile := positional[0]
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	result, err := openapi.ToObject(data, *serviceFlag)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the generated contract round-trips through the parser
+	objectDef, err := parser.ParseObjectYAML(result.Contract)
+	if err != nil {
+		return fmt.Errorf("generated contract is invalid: %w", err)
+	}
+
+	contractFile := *outputFlag
+	if contractFile == "" {
+		dir := "."
+		if *tsuboFlag != "" {
+			dir = filepath.Dir(*tsuboFlag)
+		}
+		contractFile = filepath.Join(dir, *serviceFlag+".object.yaml")
+	}
+
+	// Check the tsubo before writing anything, so a taken name leaves no file behind
+	var ref *types.ObjectRef
+	if *tsuboFlag != "" {
+		ref, err = newObjectRef(*tsuboFlag, *serviceFlag, result.Description, contractFile, *portFlag)
+		if err != nil {
+			return err
+		}
+		ref.Runtime.HealthCheck = healthCheckPath(objectDef)
+		if ref.Runtime.HealthCheck == "" {
+			result.Warnings = append(result.Warnings, "no health check endpoint found in the spec; runtime.health_check is left empty")
+		}
+	}
+
+	previous, err := os.ReadFile(contractFile)
+	existed := err == nil
+	if existed && !*forceFlag {
+		return fmt.Errorf("contract file already exists: %s (use --force to overwrite)", contractFile)
+	}
+	if err := os.WriteFile(contractFile, result.Contract, 0644); err != nil {
+		return fmt.Errorf("failed to write contract: %w", err)
+	}
+
+	if ref != nil {
+		if err := addObjectToTsubo(*tsuboFlag, ref); err != nil {
+			// Leave the contract as it was before the import
+			if existed {
+				os.WriteFile(contractFile, previous, 0644)
+			} else {
+				os.Remove(contractFile)
+			}
+			return err
+		}
+	}
+
+	fmt.Printf("%s✓ Created: %s%s\n", colorGreen, contractFile, colorReset)
+	if ref != nil {
+		fmt.Printf("%s✓ Added %s to %s (port %d)%s\n", colorGreen, ref.Name, *tsuboFlag, ref.Runtime.Port, colorReset)
+	}
+	for _, w := range result.Warnings {
+		fmt.Printf("  %s⚠ %s%s\n", colorYellow, w, colorReset)
+	}
+
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Printf("  1. Fill in the TODO placeholders in %s\n", contractFile)
+	fmt.Println("     (context.purpose, responsibilities, endpoint intents)")
+	if *tsuboFlag != "" {
+		fmt.Printf("  2. Run: potter validate %s\n", *tsuboFlag)
+	} else {
+		fmt.Println("  2. Add the service to your tsubo file (or re-run with --tsubo)")
+	}
+
+	return nil
+}
+
+// newObjectRef builds the tsubo entry of an imported service. It fails if the
+// name is already taken; without a port, the next port after the objects and
+// the gateway is used.
+func newObjectRef(tsuboFile, name, description, contractFile string, port int) (*types.ObjectRef, error) {
+	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tsubo file: %w", err)
+	}
+
+	maxPort := max(8080, tsuboDef.Gateway.Port)
+	for _, obj := range tsuboDef.Objects {
+		if obj.Name == name {
+			return nil, fmt.Errorf("object %s already exists in %s", name, tsuboFile)
+		}
+		if obj.Runtime.Port > maxPort {
+			maxPort = obj.Runtime.Port
+		}
+	}
+	if port == 0 {
+		port = maxPort + 1
+	}
+
+	contract, err := filepath.Rel(filepath.Dir(tsuboFile), contractFile)
+	if err != nil {
+		contract = contractFile
+	}
+	if !filepath.IsAbs(contract) && !strings.HasPrefix(contract, ".") {
+		contract = "./" + contract
+	}
+
+	return &types.ObjectRef{
+		Name:        name,
+		Description: strings.SplitN(description, "\n", 2)[0],
+		Contract:    filepath.ToSlash(contract),
+		Runtime: types.Runtime{
+			Type: "docker",
+			Port: port,
+		},
+	}, nil
+}
+
+// healthCheckNames are the last path segments recognized as health endpoints
+var healthCheckNames = map[string]bool{"health": true, "healthz": true, "healthcheck": true, "livez": true, "readyz": true, "ping": true}
+
+// healthCheckPath returns the path of a GET endpoint of the contract that
+// looks like a health check ("" if there is none)
+func healthCheckPath(objectDef *types.ObjectDefinition) string {
+	for _, ep := range objectDef.API.Endpoints {
+		if !strings.EqualFold(ep.Method, "GET") || strings.Contains(ep.Path, "{") {
+			continue
+		}
+		segments := strings.Split(strings.Trim(ep.Path, "/"), "/")
+		if healthCheckNames[strings.ToLower(segments[len(segments)-1])] {
+			return strings.TrimSuffix(objectDef.API.BasePath, "/") + ep.Path
+		}
+	}
+	return ""
+}
+
+// addObjectToTsubo inserts ref at the end of the tsubo's objects list.
+// The file is edited as text so that existing comments and layout are preserved.
+func addObjectToTsubo(tsuboFile string, ref *types.ObjectRef) error {
+	data, err := os.ReadFile(tsuboFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tsubo file: %w", err)
+	}
+
+	updated, err := insertObjectRef(data, ref)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(tsuboFile, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write tsubo file: %w", err)
+	}
+	return nil
+}
+
+// insertObjectRef inserts an object entry after the last item of the objects sequence
+func insertObjectRef(data []byte, ref *types.ObjectRef) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("failed to parse tsubo file: %v", err)
+	}
+	root := doc.Content[0]
+
+	var objects *yaml.Node
+	nextKeyLine := 0
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if objects != nil {
+			nextKeyLine = root.Content[i].Line
+			break
+		}
+		if root.Content[i].Value == "objects" {
+			objects = root.Content[i+1]
+		}
+	}
+	if objects == nil || objects.Kind != yaml.SequenceNode || objects.Style == yaml.FlowStyle || len(objects.Content) == 0 {
+		return nil, fmt.Errorf("cannot find a block-style objects list in the tsubo file; add %s manually", ref.Name)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+
+	// Insert after the last non-blank, non-comment line before the next top-level key
+	insertAt := len(lines)
+	if nextKeyLine > 0 {
+		insertAt = nextKeyLine - 1
+	}
+	for insertAt > 0 {
+		trimmed := strings.TrimSpace(lines[insertAt-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		insertAt--
+	}
+
+	// Items start two columns left of their first key ("- name: ...")
+	indent := strings.Repeat(" ", objects.Content[0].Column-3)
+
+	var entry strings.Builder
+	entry.WriteString("\n")
+	entry.WriteString(fmt.Sprintf("%s# Imported from OpenAPI\n", indent))
+	entry.WriteString(fmt.Sprintf("%s- name: %s\n", indent, ref.Name))
+	if ref.Description != "" {
+		entry.WriteString(fmt.Sprintf("%s  description: %q\n", indent, ref.Description))
+	}
+	entry.WriteString(fmt.Sprintf("%s  contract: %s\n", indent, ref.Contract))
+	entry.WriteString("\n")
+	entry.WriteString(fmt.Sprintf("%s  runtime:\n", indent))
+	entry.WriteString(fmt.Sprintf("%s    type: %s\n", indent, ref.Runtime.Type))
+	entry.WriteString(fmt.Sprintf("%s    port: %d\n", indent, ref.Runtime.Port))
+	if ref.Runtime.HealthCheck != "" {
+		entry.WriteString(fmt.Sprintf("%s    health_check: %s\n", indent, ref.Runtime.HealthCheck))
+	}
+	entry.WriteString("\n")
+	entry.WriteString(fmt.Sprintf("%s  dependencies: []\n", indent))
+
+	if insertAt > 0 && !strings.HasSuffix(lines[insertAt-1], "\n") {
+		lines[insertAt-1] += "\n"
+	}
+
+	var out strings.Builder
+	for _, line := range lines[:insertAt] {
+		out.WriteString(line)
+	}
+	out.WriteString(entry.String())
+	for _, line := range lines[insertAt:] {
+		out.WriteString(line)
+	}
+
+	// Make sure the result is still a valid tsubo file
+	if _, err := parser.ParseTsuboYAML([]byte(out.String())); err != nil {
+		return nil, fmt.Errorf("failed to insert %s into tsubo file: %w", ref.Name, err)
+	}
+	return []byte(out.String()), nil
+}
+
+func printImportUsage() {
+	fmt.Println("Usage: potter import <subcommand> [options] <spec>")
+	fmt.Println()
+	fmt.Println("Subcommands:")
+	fmt.Println("  openapi    Generate an object contract from an OpenAPI 3.x spec")
+	fmt.Println()
+	fmt.Println("Paths and operations become api.endpoints (operationId as id),")
+	fmt.Println("components.schemas become types and examples become semantics examples.")
+	fmt.Println("context.purpose and responsibilities are left as TODO placeholders.")
+	fmt.Println()
+	fmt.Println("Options (openapi):")
+	fmt.Println("  --service NAME    Service name (required)")
+	fmt.Println("  --tsubo FILE      Add a stub entry for the service to this tsubo file")
+	fmt.Println("  --output FILE     Contract file to write (default: <service>.object.yaml)")
+	fmt.Println("  --port N          Runtime port of the tsubo entry (default: next free port)")
+	fmt.Println("  --force           Overwrite an existing contract file")
+	fmt.Println("  --help            Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter import openapi ./billing.yaml --service billing-service")
+	fmt.Println("  potter import openapi ./billing.yaml --service billing-service --tsubo ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+}
@@ -25,6 +25,12 @@ func run() error {
 	switch command {
 	case "new":
 		return runNew(os.Args[2:])
+	case "validate":
+		return runValidate(os.Args[2:])
+	case "check":
+		return runCheck(os.Args[2:])
+	case "plan":
+		return runPlan(os.Args[2:])
 	case "build":
 		return runBuild(os.Args[2:])
 	case "verify":
@@ -39,6 +45,14 @@ func run() error {
 		return runMigrate(os.Args[2:])
 	case "refactor":
 		return runRefactor(os.Args[2:])
+	case "graph":
+		return runGraph(os.Args[2:])
+	case "impact":
+		return runImpact(os.Args[2:])
+	case "export":
+		return runExport(os.Args[2:])
+	case "import":
+		return runImport(os.Args[2:])
 	case "version", "--version", "-v":
 		fmt.Printf("potter version %s\n", version)
 		return nil
@@ -60,6 +74,9 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  new [service]              Create new service definition (default: example)")
+	fmt.Println("  validate <tsubo-file>      Validate tsubo file and object contracts")
+	fmt.Println("  check <tsubo-file>         Cross-check dependencies between contracts")
+	fmt.Println("  plan <tsubo-file>          Write the implementation plan for review")
 	fmt.Println("  build <tsubo-file>         Generate implementation plan and execute")
 	fmt.Println("  verify <tsubo-file>        Verify contract compliance and run tests")
 	fmt.Println("  run [options] <tsubo-file> Start all services with docker-compose")
@@ -67,12 +84,20 @@ func printUsage() {
 	fmt.Println("  monitor <subcommand>       Contract-driven monitoring for Kubernetes")
 	fmt.Println("  migrate <subcommand>       Detect contract changes and migrate services")
 	fmt.Println("  refactor [options]         Regenerate services cleanly from current Contract")
+	fmt.Println("  graph <tsubo-file>         Render the dependency graph (dot, mermaid, json)")
+	fmt.Println("  impact <tsubo> <service>   Show what a change to a service affects")
+	fmt.Println("  export <subcommand>        Export contracts to other formats (openapi)")
+	fmt.Println("  import <subcommand>        Import contracts from other formats (openapi)")
 	fmt.Println("  version                    Show version information")
 	fmt.Println("  help                       Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  potter new                                   # Generate example service")
 	fmt.Println("  potter new user-service                      # Create user-service template")
+	fmt.Println("  potter validate app.tsubo.yaml               # Check contracts for errors")
+	fmt.Println("  potter check app.tsubo.yaml                  # Check dependency consistency")
+	fmt.Println("  potter plan app.tsubo.yaml -o plan.json      # Write a reviewable plan")
+	fmt.Println("  potter build --plan plan.json                # Execute the reviewed plan")
 	fmt.Println("  potter build app.tsubo.yaml                  # AI-driven implementation (default)")
 	fmt.Println("  potter build --concurrency 4 app.tsubo.yaml  # Limit parallel execution")
 	fmt.Println("  potter build --prompt-only app.tsubo.yaml    # Generate prompts only")
@@ -84,5 +109,9 @@ func printUsage() {
 	fmt.Println("  potter migrate apply app.tsubo.yaml          # Apply contract changes")
 	fmt.Println("  potter refactor app.tsubo.yaml               # Regenerate all services cleanly")
 	fmt.Println("  potter refactor --service todo app.tsubo.yaml # Regenerate one service")
+	fmt.Println("  potter graph app.tsubo.yaml --format mermaid # Render the dependency graph")
+	fmt.Println("  potter impact app.tsubo.yaml user-service    # Show the blast radius of a change")
+	fmt.Println("  potter export openapi app.tsubo.yaml         # Export OpenAPI 3.1 documents")
+	fmt.Println("  potter import openapi spec.yaml --service billing --tsubo app.tsubo.yaml")
 	fmt.Println()
 }
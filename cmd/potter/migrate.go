@@ -55,6 +55,10 @@ func runMigratePlan(args []string) error {
 		return err
 	}
 
+	if err := checkDependencies(tsuboFile, tsubo, contractsDir); err != nil {
+		return err
+	}
+
 	changes, err := diff.DetectChanges(st, tsubo, contractsDir, mgr)
 	if err != nil {
 		return fmt.Errorf("failed to detect changes: %w", err)
@@ -76,6 +80,12 @@ func runMigratePlan(args []string) error {
 func runMigrateApply(args []string) error {
 	fs := flag.NewFlagSet("migrate apply", flag.ExitOnError)
 	concurrency := fs.Int("concurrency", 0, "Maximum parallel executions (0 = unlimited)")
+	model := fs.String("model", "", "Model to use (overrides potter.yaml)")
+	provider := fs.String("provider", "", "LLM provider: claude or openai (overrides potter.yaml)")
+	baseURL := fs.String("base-url", "", "LLM API base URL (overrides potter.yaml)")
+	recordDir := fs.String("record", "", "Record every LLM request/response pair as cassettes in this directory")
+	replayDir := fs.String("replay", "", "Serve LLM responses from cassettes in this directory (offline, fails on a miss)")
+	skipChecks := fs.Bool("skip-checks", false, "Don't build and vet the generated code")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -90,6 +100,10 @@ func runMigrateApply(args []string) error {
 		return err
 	}
 
+	if err := checkDependencies(tsuboFile, tsubo, contractsDir); err != nil {
+		return err
+	}
+
 	changes, err := diff.DetectChanges(st, tsubo, contractsDir, mgr)
 	if err != nil {
 		return fmt.Errorf("failed to detect changes: %w", err)
@@ -120,7 +134,26 @@ func runMigrateApply(args []string) error {
 
 	fmt.Printf("\n%s[Migrate Apply] Executing migration plan...%s\n", colorYellow, colorReset)
 
-	if err := migration.ExecuteMigration(plan, tsubo, tsuboFile, st, "", *concurrency); err != nil {
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if flagWasSet(fs, "skip-checks") {
+		config.SkipChecks = *skipChecks
+	}
+	if !flagWasSet(fs, "concurrency") {
+		*concurrency = config.Concurrency
+	}
+	llm, err := providerConfig(config, *provider, *baseURL, *model)
+	if err != nil {
+		return err
+	}
+	client, err := newImplementer(llm, *recordDir, *replayDir)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.ExecuteMigration(plan, tsubo, tsuboFile, st, client, config, *concurrency); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
@@ -196,6 +229,10 @@ func loadMigrateContext(tsuboFile string) (
 		return nil, nil, nil, "", fmt.Errorf("tsubo file not found: %s", tsuboFile)
 	}
 
+	if err = validateContracts(tsuboFile); err != nil {
+		return nil, nil, nil, "", err
+	}
+
 	tsubo, err = parser.ParseTsuboFile(tsuboFile)
 	if err != nil {
 		return nil, nil, nil, "", fmt.Errorf("failed to parse tsubo file: %w", err)
@@ -312,11 +349,7 @@ func updateStateAfterMigration(
 			}
 
 			contractPath := filepath.Join(contractsDir, objRef.Contract)
-			newHash, err := mgr.ComputeHash(contractPath)
-			if err != nil {
-				continue
-			}
-			snapshot, err := os.ReadFile(contractPath)
+			snapshot, err := mgr.SnapshotContract(contractPath)
 			if err != nil {
 				continue
 			}
@@ -329,8 +362,9 @@ func updateStateAfterMigration(
 
 			st.Services[ch.ServiceName] = &types.ServiceState{
 				ContractFile:     objRef.Contract,
-				ContractHash:     newHash,
-				ContractSnapshot: string(snapshot),
+				ContractHash:     snapshot.Hash,
+				ContractSnapshot: snapshot.Contract,
+				ImportSnapshots:  snapshot.Imports,
 				LastMigrated:     now,
 				MigrationVersion: newVersion,
 			}
@@ -376,7 +410,13 @@ func printMigrateUsage() {
 	fmt.Println("  history <tsubo-file>   Show migration history")
 	fmt.Println()
 	fmt.Println("Options (apply):")
-	fmt.Println("  --concurrency N        Maximum parallel executions (default: unlimited)")
+	fmt.Println("  --concurrency N        Maximum parallel executions (default: potter.yaml, else unlimited)")
+	fmt.Println("  --model NAME           Model to use (default: potter.yaml, else the provider's default)")
+	fmt.Println("  --provider NAME        LLM provider: claude (default) or openai")
+	fmt.Println("  --base-url URL         LLM API base URL (proxy or local model server)")
+	fmt.Println("  --record DIR           Save every LLM request/response pair as a cassette in DIR")
+	fmt.Println("  --replay DIR           Serve responses from the cassettes in DIR (no network)")
+	fmt.Println("  --skip-checks          Don't run go build / go vet on the generated code")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  potter migrate plan    poc/contracts/app.tsubo.yaml")
@@ -64,6 +64,14 @@ func runMonitorGenerate(args []string) error {
 
 	printMonitorGenerateHeader()
 
+	projectConfig, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if !flagWasSet(fs, "namespace") && projectConfig.Namespace != "" {
+		*namespace = projectConfig.Namespace
+	}
+
 	// Step 1: Parse tsubo file
 	fmt.Printf("%s[Step 1] Parsing Tsubo definition%s\n", colorYellow, colorReset)
 	tsuboDef, err := parser.ParseTsuboFile(tsuboFile)
@@ -308,7 +316,7 @@ func printMonitorGenerateUsage() {
 	fmt.Println("  potter monitor generate [options] <tsubo-file>")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --namespace string   Kubernetes namespace (default: default)")
+	fmt.Println("  --namespace string   Kubernetes namespace (default: potter.yaml, else default)")
 	fmt.Println("  --interval string    Scrape interval for ServiceMonitor (default: 15s)")
 	fmt.Println("  --help               Show this help message")
 	fmt.Println()
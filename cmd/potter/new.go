@@ -54,7 +54,8 @@ func createServiceTemplate(serviceName string) error {
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Edit the contract to define your service")
 	fmt.Println("  2. Add it to your .tsubo.yaml file")
-	fmt.Printf("  3. Run: potter build <tsubo-file> --ai\n")
+	fmt.Printf("  3. Run: potter validate <tsubo-file>\n")
+	fmt.Printf("  4. Run: potter build <tsubo-file>\n")
 	fmt.Println()
 
 	return nil
@@ -66,75 +67,97 @@ func generateObjectTemplate(serviceName string) string {
 service:
   name: %s
   description: Description of %s service
-  runtime:
-    language: go
-    version: "1.22"
+
+  context:
+    purpose: |
+      Describe why this service exists.
+    domain: %s
+    responsibilities:
+      - Create %s
+      - Get %s by ID
+    constraints:
+      - IDs are UUIDv4
 
 api:
-  type: rest
-  port: 8080
-  endpoints:
-    - path: /health
-      method: GET
-      description: Health check endpoint
-      response:
-        type: object
-        properties:
-          status:
-            type: string
-            example: "healthy"
+  version: "1.0.0"
+  base_path: /api/v1
 
-    - path: /%s
+  endpoints:
+    - id: create_%s
       method: POST
-      description: Create a new %s
+      path: /%s
       request:
-        type: object
-        properties:
-          name:
-            type: string
-            required: true
+        content_type: application/json
+        schema:
+          type: object
+          required: [name]
+          properties:
+            name:
+              type: string
+              minLength: 1
       response:
-        type: object
-        properties:
-          id:
-            type: string
-          name:
-            type: string
-
-    - path: /%s/{id}
+        201:
+          description: Created
+          schema: {$ref: "#/types/%s"}
+        400:
+          description: Invalid request
+          schema: {$ref: "#/types/Error"}
+      semantics:
+        intent: Create a new %s
+
+    - id: get_%s
       method: GET
-      description: Get %s by ID
-      response:
-        type: object
-        properties:
+      path: /%s/{id}
+      request:
+        path_params:
           id:
             type: string
-          name:
-            type: string
+            format: uuid
+      response:
+        200:
+          schema: {$ref: "#/types/%s"}
+        404:
+          schema: {$ref: "#/types/Error"}
+      semantics:
+        intent: Get %s by ID
+
+types:
+  %s:
+    description: A %s
+    properties:
+      id:
+        type: string
+        format: uuid
+      name:
+        type: string
+
+  Error:
+    description: Error response
+    properties:
+      error:
+        type: string
 
 dependencies:
   services: []
     # Example:
     # - name: other-service
-    #   endpoint: http://other-service:8080
+    #   reason: Why this service is needed
+    #   endpoints: ["/other/validate"]
 
   databases:
-    - type: postgres
-      name: %s_db
-      schema:
-        tables:
-          - name: %ss
-            columns:
-              - name: id
-                type: uuid
-                primary_key: true
-              - name: name
-                type: varchar(255)
-                required: true
-              - name: created_at
-                type: timestamp
-                default: now()
-`, serviceName, serviceName, serviceName, serviceName, serviceName, serviceName, serviceName, serviceName)
+    - name: %s-db
+      type: in-memory
+      tables: [%s]
+
+performance:
+  latency:
+    p50: 50ms
+    p95: 100ms
+    p99: 200ms
+`, serviceName, serviceName, serviceName, serviceName, serviceName,
+		serviceName, serviceName, serviceName, serviceName,
+		serviceName, serviceName, serviceName, serviceName,
+		serviceName, serviceName, serviceName, serviceName)
 }
 
 func printNewUsage() {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/staka121/potter/internal/executor"
+	"github.com/staka121/potter/pkg/types"
+)
+
+func runPlan(args []string) error {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	output := fs.String("output", "plan.json", "Plan file to write")
+	fs.StringVar(output, "o", "plan.json", "Plan file to write (shorthand)")
+	helpFlag := fs.Bool("help", false, "Show help for plan command")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printPlanUsage()
+		return nil
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("tsubo file path required. Usage: potter plan <tsubo-file> [-o plan.json]")
+	}
+
+	tsuboFile := positional[0]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+
+	if err := validateContracts(tsuboFile); err != nil {
+		return err
+	}
+
+	plan, err := generatePlan(tsuboFile, config)
+	if err != nil {
+		return fmt.Errorf("failed to generate plan: %w", err)
+	}
+
+	if err := executor.SavePlan(plan, *output); err != nil {
+		return err
+	}
+
+	printPlan(plan)
+
+	fmt.Println()
+	fmt.Printf("%s✓ Plan written to %s%s\n", colorGreen, *output, colorReset)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Printf("  1. Review %s\n", *output)
+	fmt.Printf("  2. Run: potter build --plan %s\n", *output)
+	return nil
+}
+
+// printPlan prints the waves and contract hashes of a plan
+func printPlan(plan *types.ImplementationPlan) {
+	fmt.Printf("\n%sTsubo: %s%s\n", colorBlue, plan.Tsubo, colorReset)
+	fmt.Printf("Implementations: %s\n", plan.ImplementationsDir)
+	fmt.Printf("Objects: %d in %d wave(s)\n", countObjects(plan), len(plan.Waves))
+
+	for _, wave := range plan.Waves {
+		fmt.Printf("\n  Wave %d:\n", wave.Wave)
+		for _, obj := range wave.Objects {
+			fmt.Printf("    - %s", obj.Name)
+			if obj.IsGateway {
+				fmt.Print(" (auto-generated gateway)")
+			}
+			if len(obj.Dependencies) > 0 {
+				fmt.Printf(" (depends on: %v)", obj.Dependencies)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(plan.ContractHashes) > 0 {
+		fmt.Println("\n  Contract hashes:")
+		names := make([]string, 0, len(plan.ContractHashes))
+		for name := range plan.ContractHashes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("    %s: %.12s\n", name, plan.ContractHashes[name])
+		}
+	}
+}
+
+func printPlanUsage() {
+	fmt.Println("Usage: potter plan <tsubo-file> [options]")
+	fmt.Println()
+	fmt.Println("Generates the implementation plan and writes it as JSON for review.")
+	fmt.Println("The plan records the hash of the tsubo file and every contract;")
+	fmt.Println("'potter build --plan' refuses to run it if any of them changed.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o, --output FILE   Plan file to write (default: plan.json)")
+	fmt.Println("  --help              Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter plan app.tsubo.yaml -o plan.json")
+	fmt.Println("  potter build --plan plan.json")
+}
@@ -3,11 +3,11 @@ package main
 import (
 	"flag"
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/staka121/potter/internal/executor"
+	"github.com/staka121/potter/internal/planner"
 	"github.com/staka121/potter/pkg/state"
 	"github.com/staka121/potter/pkg/types"
 )
@@ -16,6 +16,12 @@ func runRefactor(args []string) error {
 	fs := flag.NewFlagSet("refactor", flag.ExitOnError)
 	serviceFlag := fs.String("service", "", "Specific service to refactor (default: all services)")
 	concurrency := fs.Int("concurrency", 0, "Maximum parallel executions (0 = unlimited)")
+	model := fs.String("model", "", "Model to use (overrides potter.yaml)")
+	provider := fs.String("provider", "", "LLM provider: claude or openai (overrides potter.yaml)")
+	baseURL := fs.String("base-url", "", "LLM API base URL (overrides potter.yaml)")
+	recordDir := fs.String("record", "", "Record every LLM request/response pair as cassettes in this directory")
+	replayDir := fs.String("replay", "", "Serve LLM responses from cassettes in this directory (offline, fails on a miss)")
+	skipChecks := fs.Bool("skip-checks", false, "Don't build and vet the generated code")
 	helpFlag := fs.Bool("help", false, "Show help for refactor command")
 
 	if err := fs.Parse(args); err != nil {
@@ -32,11 +38,35 @@ func runRefactor(args []string) error {
 		return err
 	}
 
+	// Validate contracts before regenerating anything from them
+	if err := validateContracts(tsuboFile); err != nil {
+		return err
+	}
+
 	tsubo, mgr, st, contractsDir, err := loadMigrateContext(tsuboFile)
 	if err != nil {
 		return err
 	}
 
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	if flagWasSet(fs, "skip-checks") {
+		config.SkipChecks = *skipChecks
+	}
+	if !flagWasSet(fs, "concurrency") {
+		*concurrency = config.Concurrency
+	}
+	llm, err := providerConfig(config, *provider, *baseURL, *model)
+	if err != nil {
+		return err
+	}
+	client, err := newImplementer(llm, *recordDir, *replayDir)
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf("\n%s========================================%s\n", colorBlue, colorReset)
 	fmt.Printf("%sPotter Refactor%s\n", colorBlue, colorReset)
 	fmt.Printf("%s========================================%s\n\n", colorBlue, colorReset)
@@ -61,24 +91,22 @@ func runRefactor(args []string) error {
 		fmt.Printf("  Refactoring all %d service(s)\n\n", len(targets))
 	}
 
-	implementationsDir := filepath.Join(filepath.Dir(tsuboFile), "implementations")
-	projectRoot := filepath.Join(contractsDir, "..", "..")
-
 	var changeRecords []types.ChangeRecord
 	now := time.Now()
 
 	for _, obj := range targets {
 		fmt.Printf("  🔨 Refactoring: %s\n", obj.Name)
 
-		plan := buildSingleServicePlanForRefactor(tsubo, tsuboFile, contractsDir, implementationsDir, projectRoot, obj)
+		plan := buildSingleServicePlanForRefactor(tsubo, tsuboFile, contractsDir, config, obj)
 
-		runner, err := executor.NewRunner(plan)
+		runner, err := executor.NewRunner(plan, client)
 		if err != nil {
 			return fmt.Errorf("failed to create runner for %s: %w", obj.Name, err)
 		}
 		if *concurrency > 0 {
 			runner.SetConcurrency(*concurrency)
 		}
+		runner.SetRepair(executor.NewRepairPolicy(config))
 
 		result, err := runner.ExecuteSingle(obj.Name)
 		if err != nil {
@@ -127,8 +155,7 @@ func buildSingleServicePlanForRefactor(
 	tsubo *types.TsuboDefinition,
 	tsuboFile string,
 	contractsDir string,
-	implementationsDir string,
-	projectRoot string,
+	config *types.ProjectConfig,
 	obj types.ObjectRef,
 ) *types.ImplementationPlan {
 	wave := types.Wave{
@@ -149,31 +176,15 @@ func buildSingleServicePlanForRefactor(
 		Tsubo:              tsubo.Tsubo.Name,
 		TsuboFile:          tsuboFile,
 		ContractsDir:       contractsDir,
-		ProjectRoot:        projectRoot,
-		ImplementationsDir: implementationsDir,
-		ContextFiles:       getRefactorContextFiles(projectRoot),
+		ProjectRoot:        config.ProjectRoot,
+		ImplementationsDir: config.ImplementationsDir,
+		ArtifactsDir:       config.ArtifactsDir,
+		ContextFiles:       planner.GetContextFiles(config.ContextFiles),
+		Network:            tsubo.Deployment.Network,
 		Waves:              []types.Wave{wave},
 	}
 }
 
-// getRefactorContextFiles returns existing doc files
-func getRefactorContextFiles(projectRoot string) []string {
-	candidates := []string{
-		filepath.Join(projectRoot, "docs", "PHILOSOPHY.md"),
-		filepath.Join(projectRoot, "docs", "DEVELOPMENT_PRINCIPLES.md"),
-		filepath.Join(projectRoot, "docs", "WHY_GO.md"),
-		filepath.Join(projectRoot, "docs", "CONTRACT_DESIGN.md"),
-	}
-
-	var existing []string
-	for _, f := range candidates {
-		if _, err := os.Stat(f); err == nil {
-			existing = append(existing, f)
-		}
-	}
-	return existing
-}
-
 // loadStateForRefactor is a helper alias used in refactor.go (uses the shared loadMigrateContext)
 var _ = func(tsuboFile string) (*state.Manager, *types.PotterState, error) {
 	_, mgr, st, _, err := loadMigrateContext(tsuboFile)
@@ -188,7 +199,13 @@ func printRefactorUsage() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --service <name>   Refactor only this service (default: all services)")
-	fmt.Println("  --concurrency N    Maximum parallel executions (default: unlimited)")
+	fmt.Println("  --concurrency N    Maximum parallel executions (default: potter.yaml, else unlimited)")
+	fmt.Println("  --model NAME       Model to use (default: potter.yaml, else the provider's default)")
+	fmt.Println("  --provider NAME    LLM provider: claude (default) or openai")
+	fmt.Println("  --base-url URL     LLM API base URL (proxy or local model server)")
+	fmt.Println("  --record DIR       Save every LLM request/response pair as a cassette in DIR")
+	fmt.Println("  --replay DIR       Serve responses from the cassettes in DIR (no network)")
+	fmt.Println("  --skip-checks      Don't run go build / go vet on the generated code")
 	fmt.Println("  --help             Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
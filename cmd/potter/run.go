@@ -6,10 +6,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/database"
+	"github.com/staka121/potter/pkg/state"
+	"github.com/staka121/potter/pkg/types"
 )
 
 func runRun(args []string) error {
@@ -39,8 +43,11 @@ func runRun(args []string) error {
 	}
 
 	tsuboFile := remainingArgs[0]
-	tsuboDir := filepath.Dir(tsuboFile)
-	implDir := filepath.Join(tsuboDir, "implementations")
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	implDir := config.ImplementationsDir
 
 	if _, err := os.Stat(implDir); os.IsNotExist(err) {
 		return fmt.Errorf("implementations directory not found: %s\nRun 'potter build %s' first to generate implementations", implDir, tsuboFile)
@@ -53,19 +60,19 @@ func runRun(args []string) error {
 	}
 
 	// Create Docker network if it doesn't exist
-	// Using the network name defined in tsubo.yaml deployment.network.name
-	networkName := "tsubo-network"
-	fmt.Printf("Ensuring Docker network '%s' exists...\n", networkName)
+	// Using the network declared in tsubo.yaml deployment.network
+	network := tsuboDef.Deployment.Network
+	fmt.Printf("Ensuring Docker network '%s' (driver: %s) exists...\n", network.Name, network.Driver)
 
 	// Check if network exists
-	checkCmd := exec.Command("docker", "network", "inspect", networkName)
+	checkCmd := exec.Command("docker", "network", "inspect", network.Name)
 	if err := checkCmd.Run(); err != nil {
 		// Network doesn't exist, create it
-		createCmd := exec.Command("docker", "network", "create", networkName)
+		createCmd := exec.Command("docker", "network", "create", "--driver", network.Driver, network.Name)
 		createCmd.Stdout = os.Stdout
 		createCmd.Stderr = os.Stderr
 		if err := createCmd.Run(); err != nil {
-			return fmt.Errorf("failed to create network %s: %w", networkName, err)
+			return fmt.Errorf("failed to create network %s: %w", network.Name, err)
 		}
 		fmt.Printf("  %s✓ Network created%s\n", colorGreen, colorReset)
 	} else {
@@ -73,11 +80,6 @@ func runRun(args []string) error {
 	}
 	fmt.Println()
 
-	// Build service list based on dependencies
-	// First, services without dependencies, then services with dependencies
-	var servicesNoDeps []string
-	var servicesWithDeps []string
-
 	// Create a map for quick lookup
 	serviceMap := make(map[string]bool)
 	entries, err := os.ReadDir(implDir)
@@ -90,27 +92,19 @@ func runRun(args []string) error {
 		}
 	}
 
-	// Separate services by dependencies
-	for _, objRef := range tsuboDef.Objects {
+	var services []string
+	for _, name := range startupOrder(tsuboDef) {
 		// Skip if not in implementations directory
-		if !serviceMap[objRef.Name] {
+		if !serviceMap[name] {
 			continue
 		}
 		// Filter by service name if specified
-		if *serviceFlag != "" && objRef.Name != *serviceFlag {
+		if *serviceFlag != "" && name != *serviceFlag {
 			continue
 		}
-
-		if len(objRef.Dependencies) == 0 {
-			servicesNoDeps = append(servicesNoDeps, objRef.Name)
-		} else {
-			servicesWithDeps = append(servicesWithDeps, objRef.Name)
-		}
+		services = append(services, name)
 	}
 
-	// Combine: services without dependencies first, then services with dependencies
-	services := append(servicesNoDeps, servicesWithDeps...)
-
 	if len(services) == 0 {
 		if *serviceFlag != "" {
 			return fmt.Errorf("service not found: %s", *serviceFlag)
@@ -118,6 +112,11 @@ func runRun(args []string) error {
 		return fmt.Errorf("no services found in %s", implDir)
 	}
 
+	// Start the databases declared in the contracts before the services that use them
+	if err := provisionDatabases(tsuboFile, tsuboDef, implDir, services); err != nil {
+		return err
+	}
+
 	fmt.Printf("Starting %d service(s)...\n\n", len(services))
 
 	// Always start services in detached mode first
@@ -151,14 +150,15 @@ func runRun(args []string) error {
 		fmt.Println()
 	}
 
-	// Auto-start gateway-service if it exists (implicit API Gateway for Tsubo encapsulation)
+	// Auto-start the gateway if it is enabled and implemented (implicit API Gateway for Tsubo encapsulation)
 	// Gateway is started last, after all other services are up
-	if *serviceFlag == "" {
-		gatewayDir := filepath.Join(implDir, "gateway-service")
+	gateway := tsuboDef.Gateway
+	if *serviceFlag == "" && parser.GatewayEnabled(tsuboDef) {
+		gatewayDir := filepath.Join(implDir, gateway.Name)
 		gatewayComposeFile := filepath.Join(gatewayDir, "docker-compose.yml")
 
 		if _, err := os.Stat(gatewayComposeFile); err == nil {
-			fmt.Printf("%s[gateway-service]%s (auto-generated API Gateway)\n", colorYellow, colorReset)
+			fmt.Printf("%s[%s]%s (auto-generated API Gateway)\n", colorYellow, gateway.Name, colorReset)
 
 			cmd := exec.Command("docker", "compose", "up", "-d")
 			cmd.Dir = gatewayDir
@@ -166,16 +166,16 @@ func runRun(args []string) error {
 			cmd.Stderr = os.Stderr
 
 			if err := cmd.Run(); err != nil {
-				fmt.Printf("  %s✗ Failed to start gateway-service%s\n", colorRed, colorReset)
-				return fmt.Errorf("failed to start gateway-service: %w", err)
+				fmt.Printf("  %s✗ Failed to start %s%s\n", colorRed, gateway.Name, colorReset)
+				return fmt.Errorf("failed to start %s: %w", gateway.Name, err)
 			}
 
-			fmt.Printf("  %s✓ Gateway started (port 8080)%s\n", colorGreen, colorReset)
+			fmt.Printf("  %s✓ Gateway started (port %d)%s\n", colorGreen, gateway.Port, colorReset)
 			fmt.Printf("  %s壺（Tsubo）のエントリーポイント起動完了%s\n", colorGreen, colorReset)
 			fmt.Println()
 
 			// Add gateway to services list for log viewing
-			services = append(services, "gateway-service")
+			services = append(services, gateway.Name)
 		}
 	}
 
@@ -233,11 +233,163 @@ func runRun(args []string) error {
 	return nil
 }
 
+// startupOrder returns the order in which services are started.
+// An explicit deployment.startup_order is honored; objects it does not list
+// are started afterwards. Without it, services without dependencies start
+// first, then services with dependencies.
+func startupOrder(tsuboDef *types.TsuboDefinition) []string {
+	var order []string
+	listed := make(map[string]bool)
+
+	if len(tsuboDef.Deployment.StartupOrder) > 0 {
+		known := make(map[string]bool)
+		for _, objRef := range tsuboDef.Objects {
+			known[objRef.Name] = true
+		}
+		for _, name := range tsuboDef.Deployment.StartupOrder {
+			if !known[name] {
+				fmt.Printf("%s⚠ startup_order refers to unknown object %s, skipping%s\n", colorYellow, name, colorReset)
+				continue
+			}
+			if !listed[name] {
+				order = append(order, name)
+				listed[name] = true
+			}
+		}
+	}
+
+	// Services without dependencies first, then services with dependencies
+	var servicesNoDeps []string
+	var servicesWithDeps []string
+	for _, objRef := range tsuboDef.Objects {
+		if listed[objRef.Name] {
+			continue
+		}
+		if len(objRef.Dependencies) == 0 {
+			servicesNoDeps = append(servicesNoDeps, objRef.Name)
+		} else {
+			servicesWithDeps = append(servicesWithDeps, objRef.Name)
+		}
+	}
+
+	order = append(order, servicesNoDeps...)
+	return append(order, servicesWithDeps...)
+}
+
+// provisionDatabases starts a container per postgres/mysql/redis database declared by
+// the given services, waits until they are healthy, and writes each connection
+// string to the service's .env file so docker-compose.yml can pass it through
+func provisionDatabases(tsuboFile string, tsuboDef *types.TsuboDefinition, implDir string, services []string) error {
+	databases, err := database.ForTsubo(tsuboDef, parser.GetContractsDir(tsuboFile))
+	if err != nil {
+		return err
+	}
+
+	mgr := state.NewManager(tsuboFile)
+	var instances []database.Instance
+	for _, service := range services {
+		if err := database.AssignCredentials(databases[service], mgr.Secret); err != nil {
+			return err
+		}
+		instances = append(instances, databases[service]...)
+	}
+	if len(instances) == 0 {
+		return nil
+	}
+
+	fmt.Printf("%s[databases]%s\n", colorYellow, colorReset)
+
+	composeDir := filepath.Join(implDir, database.ComposeDirName)
+	if err := os.MkdirAll(composeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", composeDir, err)
+	}
+	// The compose file and the .env files contain the database passwords
+	if err := state.EnsureGitignore(composeDir, "Generated with database passwords: never commit this directory", "*"); err != nil {
+		return err
+	}
+	if err := state.EnsureGitignore(implDir, "Database URLs with passwords, written by potter run", ".env"); err != nil {
+		return err
+	}
+	compose, err := database.Compose(instances, tsuboDef.Deployment.Network.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(composeDir, "docker-compose.yml"), compose, 0600); err != nil {
+		return fmt.Errorf("failed to write database compose file: %w", err)
+	}
+
+	cmdArgs := []string{"compose", "up", "-d", "--wait"}
+	for _, inst := range instances {
+		cmdArgs = append(cmdArgs, inst.Host)
+	}
+	cmd := exec.Command("docker", cmdArgs...)
+	cmd.Dir = composeDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("  %s✗ Failed to start databases%s\n", colorRed, colorReset)
+		return fmt.Errorf("failed to start databases: %w", err)
+	}
+
+	for _, service := range services {
+		vars := make(map[string]string)
+		for _, inst := range databases[service] {
+			vars[inst.EnvVar()] = inst.URL(inst.Host)
+			fmt.Printf("  %s✓ %s (%s) for %s → %s%s\n", colorGreen, inst.Host, inst.Type, service, inst.EnvVar(), colorReset)
+		}
+		if len(vars) == 0 {
+			continue
+		}
+		if err := mergeEnvFile(filepath.Join(implDir, service, ".env"), vars); err != nil {
+			return err
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// mergeEnvFile sets vars in a .env file, keeping any other lines already in it
+func mergeEnvFile(path string, vars map[string]string) error {
+	var lines []string
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			name, _, _ := strings.Cut(line, "=")
+			if _, ok := vars[strings.TrimSpace(name)]; !ok {
+				lines = append(lines, line)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s=%s", name, vars[name]))
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
 func printRunUsage() {
 	fmt.Println("Usage: potter run [options] <tsubo-file>")
 	fmt.Println()
 	fmt.Println("Starts all services using docker-compose")
 	fmt.Println()
+	fmt.Println("The Docker network and startup order are taken from the tsubo")
+	fmt.Println("deployment section (deployment.network, deployment.startup_order).")
+	fmt.Println()
+	fmt.Println("postgres, mysql and redis databases declared in the contracts are started")
+	fmt.Println("first (one container, database and user per service) and their connection")
+	fmt.Println("strings are written to each service's .env file (e.g. USER_DB_URL).")
+	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -d                Run in detached mode (background)")
 	fmt.Println("  --service NAME    Run specific service only")
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/staka121/potter/pkg/validation"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	helpFlag := fs.Bool("help", false, "Show help for validate command")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *helpFlag {
+		printValidateUsage()
+		return nil
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("tsubo file path required. Usage: potter validate <tsubo-file>")
+	}
+
+	tsuboFile := args[0]
+
+	if _, err := os.Stat(tsuboFile); os.IsNotExist(err) {
+		return fmt.Errorf("tsubo file not found: %s", tsuboFile)
+	}
+
+	result := validation.ValidateTsuboFile(tsuboFile)
+	printValidationIssues(result)
+
+	if result.HasErrors() {
+		return fmt.Errorf("validation failed: %d error(s), %d warning(s)", result.ErrorCount(), result.WarningCount())
+	}
+
+	fmt.Printf("%s✓ %s is valid", colorGreen, tsuboFile)
+	if result.WarningCount() > 0 {
+		fmt.Printf(" (%d warning(s))", result.WarningCount())
+	}
+	fmt.Printf("%s\n", colorReset)
+	return nil
+}
+
+// validateContracts validates the tsubo file and its contracts before a command runs.
+// Warnings are printed; errors abort the command.
+func validateContracts(tsuboFile string) error {
+	result := validation.ValidateTsuboFile(tsuboFile)
+	printValidationIssues(result)
+
+	if result.HasErrors() {
+		return fmt.Errorf("contract validation failed with %d error(s). Run 'potter validate %s' for details", result.ErrorCount(), tsuboFile)
+	}
+	return nil
+}
+
+// printValidationIssues prints each issue in file:line:column format
+func printValidationIssues(result *validation.Result) {
+	for _, issue := range result.Issues {
+		color := colorYellow
+		if issue.Severity == validation.SeverityError {
+			color = colorRed
+		}
+		fmt.Printf("%s%s%s\n", color, issue, colorReset)
+	}
+}
+
+func printValidateUsage() {
+	fmt.Println("Usage: potter validate <tsubo-file>")
+	fmt.Println()
+	fmt.Println("Validates the tsubo file and every object contract it references.")
+	fmt.Println("Problems are reported as file:line:column. Exits non-zero on errors.")
+	fmt.Println()
+	fmt.Println("Checks:")
+	fmt.Println("  - Required fields and unknown keys")
+	fmt.Println("  - Duplicate endpoint IDs, routes and object names")
+	fmt.Println("  - HTTP methods, paths and response status codes")
+	fmt.Println("  - Latency thresholds (e.g. \"50ms\")")
+	fmt.Println("  - Referenced contract and architecture files")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --help            Show this help message")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter validate ./poc/contracts/tsubo-todo-app.tsubo.yaml")
+}
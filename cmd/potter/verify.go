@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/staka121/potter/internal/parser"
 )
 
 func runVerify(args []string) error {
@@ -35,8 +37,11 @@ func runVerify(args []string) error {
 	}
 
 	tsuboFile := remainingArgs[0]
-	tsuboDir := filepath.Dir(tsuboFile)
-	implDir := filepath.Join(tsuboDir, "implementations")
+	config, err := loadProjectConfig(tsuboFile)
+	if err != nil {
+		return err
+	}
+	implDir := config.ImplementationsDir
 
 	if _, err := os.Stat(implDir); os.IsNotExist(err) {
 		return fmt.Errorf("implementations directory not found: %s\nRun 'potter build %s' first to generate implementations", implDir, tsuboFile)
@@ -106,6 +111,17 @@ func runVerify(args []string) error {
 		}
 	}
 
+	// List tsubo-wide integration tests declared in the tsubo file
+	if *serviceFlag == "" {
+		if tsuboDef, err := parser.ParseTsuboFile(tsuboFile); err == nil && len(tsuboDef.IntegrationTests) > 0 {
+			fmt.Printf("%sIntegration tests (declared in %s, verify manually):%s\n", colorYellow, filepath.Base(tsuboFile), colorReset)
+			for _, test := range tsuboDef.IntegrationTests {
+				fmt.Printf("  - %s: %s\n", test.Name, test.Description)
+			}
+			fmt.Println()
+		}
+	}
+
 	// Summary
 	fmt.Printf("%s========================================%s\n", colorBlue, colorReset)
 	fmt.Printf("%sVerification Summary%s\n", colorBlue, colorReset)
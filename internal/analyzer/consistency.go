@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// Diagnostic codes reported by CheckDependencies
+const (
+	DiagUnknownObject     = "unknown_object"      // Dependency target is not an object in the tsubo
+	DiagUnknownEndpoint   = "unknown_endpoint"    // Referenced endpoint does not exist in the target contract
+	DiagMissingInTsubo    = "missing_in_tsubo"    // Contract declares a dependency the tsubo does not
+	DiagMissingInContract = "missing_in_contract" // Tsubo declares a dependency the contract does not
+	DiagSelfDependency    = "self_dependency"     // Object depends on itself
+	DiagExternalIsObject  = "external_is_object"  // Dependency declared external is an object in the tsubo
+)
+
+// Diagnostic severities. Only errors fail a check.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic describes a dependency inconsistency between the tsubo and its contracts
+type Diagnostic struct {
+	Severity   string `json:"severity"` // "error" | "warning"
+	Code       string `json:"code"`
+	Object     string `json:"object"`             // Object whose declaration is inconsistent
+	Dependency string `json:"dependency"`         // Dependency target name
+	Endpoint   string `json:"endpoint,omitempty"` // Endpoint reference (unknown_endpoint only)
+	Message    string `json:"message"`
+}
+
+// String formats the diagnostic for display
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Object, d.Message)
+}
+
+// HasErrors returns true if any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	return ErrorCount(diags) > 0
+}
+
+// ErrorCount returns the number of error diagnostics
+func ErrorCount(diags []Diagnostic) int {
+	return countSeverity(diags, SeverityError)
+}
+
+// WarningCount returns the number of warning diagnostics
+func WarningCount(diags []Diagnostic) int {
+	return countSeverity(diags, SeverityWarning)
+}
+
+func countSeverity(diags []Diagnostic, severity string) int {
+	count := 0
+	for _, d := range diags {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// pathParamPattern matches path parameters such as {id}
+var pathParamPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// CheckDependencies cross-checks every declared dependency between objects.
+// It verifies that dependency targets exist in the tsubo, that referenced
+// endpoints exist in the target contract, and that the tsubo and contract
+// dependency lists agree. The contract is authoritative, so a tsubo list that
+// drifted from it is only a warning.
+func CheckDependencies(tsubo *types.TsuboDefinition, contractsDir string) ([]Diagnostic, error) {
+	contracts := make(map[string]*types.ObjectDefinition)
+	objectNames := make([]string, 0, len(tsubo.Objects))
+	for _, objRef := range tsubo.Objects {
+		objectNames = append(objectNames, objRef.Name)
+		contractPath := filepath.Join(contractsDir, objRef.Contract)
+		objectDef, err := parser.ParseObjectFile(contractPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", objRef.Contract, err)
+		}
+		contracts[objRef.Name] = objectDef
+	}
+
+	var diags []Diagnostic
+
+	for _, objRef := range tsubo.Objects {
+		objectDef := contracts[objRef.Name]
+
+		contractDeps := make(map[string]bool)
+		externalDeps := make(map[string]bool)
+		for _, dep := range objectDef.Dependencies.Services {
+			if dep.External {
+				externalDeps[dep.Name] = true
+				if _, exists := contracts[dep.Name]; exists {
+					diags = append(diags, Diagnostic{
+						Severity:   SeverityError,
+						Code:       DiagExternalIsObject,
+						Object:     objRef.Name,
+						Dependency: dep.Name,
+						Message:    fmt.Sprintf("contract declares %s as external, but it is an object in the tsubo", dep.Name),
+					})
+				}
+				continue
+			}
+			contractDeps[dep.Name] = true
+
+			if dep.Name == objRef.Name {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityError,
+					Code:       DiagSelfDependency,
+					Object:     objRef.Name,
+					Dependency: dep.Name,
+					Message:    "contract declares a dependency on itself",
+				})
+				continue
+			}
+
+			target, exists := contracts[dep.Name]
+			if !exists {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityError,
+					Code:       DiagUnknownObject,
+					Object:     objRef.Name,
+					Dependency: dep.Name,
+					Message:    unknownObjectMessage("contract depends on "+dep.Name, dep.Name, objectNames),
+				})
+				continue
+			}
+
+			for _, ref := range dep.Endpoints {
+				if !hasEndpoint(target, ref) {
+					diags = append(diags, Diagnostic{
+						Severity:   SeverityError,
+						Code:       DiagUnknownEndpoint,
+						Object:     objRef.Name,
+						Dependency: dep.Name,
+						Endpoint:   ref,
+						Message:    fmt.Sprintf("endpoint %q is not defined in the %s contract", ref, dep.Name),
+					})
+				}
+			}
+		}
+
+		tsuboDeps := make(map[string]bool)
+		for _, dep := range objRef.Dependencies {
+			tsuboDeps[dep] = true
+
+			if dep == objRef.Name {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityError,
+					Code:       DiagSelfDependency,
+					Object:     objRef.Name,
+					Dependency: dep,
+					Message:    "tsubo lists the object as its own dependency",
+				})
+				continue
+			}
+
+			if _, exists := contracts[dep]; !exists && !externalDeps[dep] {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityError,
+					Code:       DiagUnknownObject,
+					Object:     objRef.Name,
+					Dependency: dep,
+					Message:    unknownObjectMessage("tsubo lists dependency "+dep, dep, objectNames),
+				})
+			}
+		}
+
+		for _, dep := range sortedNames(contractDeps) {
+			if !tsuboDeps[dep] && dep != objRef.Name {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityWarning,
+					Code:       DiagMissingInTsubo,
+					Object:     objRef.Name,
+					Dependency: dep,
+					Message:    fmt.Sprintf("contract depends on %s but the tsubo dependencies do not list it", dep),
+				})
+			}
+		}
+
+		for _, dep := range sortedNames(tsuboDeps) {
+			if !contractDeps[dep] && !externalDeps[dep] && dep != objRef.Name {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityWarning,
+					Code:       DiagMissingInContract,
+					Object:     objRef.Name,
+					Dependency: dep,
+					Message:    fmt.Sprintf("tsubo lists dependency %s but the contract does not declare it in dependencies.services", dep),
+				})
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+// unknownObjectMessage explains an unknown dependency, suggesting a likely intended object
+func unknownObjectMessage(prefix, name string, objectNames []string) string {
+	msg := prefix + ", which is not an object in the tsubo"
+	if suggestion := SuggestName(name, objectNames); suggestion != "" {
+		return msg + fmt.Sprintf(" (did you mean %s?)", suggestion)
+	}
+	return msg + " (mark it external: true in the contract if it is provided outside the tsubo)"
+}
+
+// hasEndpoint reports whether a contract defines the referenced endpoint
+func hasEndpoint(contract *types.ObjectDefinition, ref string) bool {
+	return FindEndpoint(contract, ref) != nil
+}
+
+// FindEndpoint returns the contract endpoint a reference points to (nil if none).
+// A reference may be an endpoint ID ("validate_user"), a route
+// ("POST /users/validate") or a bare path ("/users/validate").
+// Paths match with or without the contract's base_path, and path
+// parameter names are ignored ("/users/{id}" matches "/users/{user_id}").
+func FindEndpoint(contract *types.ObjectDefinition, ref string) *types.Endpoint {
+	ref = strings.TrimSpace(ref)
+
+	method, path := "", ref
+	if fields := strings.Fields(ref); len(fields) == 2 {
+		method, path = strings.ToUpper(fields[0]), fields[1]
+	}
+
+	for i := range contract.API.Endpoints {
+		ep := &contract.API.Endpoints[i]
+		if ep.ID == ref {
+			return ep
+		}
+		if !strings.HasPrefix(path, "/") {
+			continue
+		}
+		if method != "" && !strings.EqualFold(ep.Method, method) {
+			continue
+		}
+		want := normalizePath(path)
+		if normalizePath(ep.Path) == want || normalizePath(contract.API.BasePath+ep.Path) == want {
+			return ep
+		}
+	}
+	return nil
+}
+
+// normalizePath removes trailing slashes and path parameter names
+func normalizePath(path string) string {
+	path = pathParamPattern.ReplaceAllString(path, "{}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
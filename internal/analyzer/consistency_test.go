@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+const userContract = `service:
+  name: user-service
+api:
+  base_path: /api/v1
+  endpoints:
+    - id: get-user
+      method: GET
+      path: /users/{id}
+`
+
+const todoContract = `service:
+  name: todo-service
+dependencies:
+  services:
+    - name: user-service
+      endpoints: [get-user, "GET /api/v1/users/{userId}", "DELETE /users/{id}"]
+    - name: mail-service
+      external: true
+`
+
+func writeContracts(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"user.object.yaml": userContract, "todo.object.yaml": todoContract} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testTsubo(todoDeps ...string) *types.TsuboDefinition {
+	return &types.TsuboDefinition{Objects: []types.ObjectRef{
+		{Name: "user-service", Contract: "user.object.yaml"},
+		{Name: "todo-service", Contract: "todo.object.yaml", Dependencies: todoDeps},
+	}}
+}
+
+func diagCodes(diags []Diagnostic) map[string]string {
+	codes := make(map[string]string)
+	for _, d := range diags {
+		codes[d.Code+" "+d.Dependency+" "+d.Endpoint] = d.Severity
+	}
+	return codes
+}
+
+func TestCheckDependencies(t *testing.T) {
+	diags, err := CheckDependencies(testTsubo("user-service", "mail-service"), writeContracts(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the endpoint that user-service does not define is reported
+	key := DiagUnknownEndpoint + " user-service DELETE /users/{id}"
+	if got := diagCodes(diags); len(got) != 1 || got[key] != SeverityError {
+		t.Errorf("diagnostics = %v, want only an error for %s", got, key)
+	}
+	if !HasErrors(diags) || ErrorCount(diags) != 1 || WarningCount(diags) != 0 {
+		t.Errorf("errors = %d, warnings = %d; want 1, 0", ErrorCount(diags), WarningCount(diags))
+	}
+}
+
+func TestCheckDependenciesTsuboDrift(t *testing.T) {
+	contractsDir := writeContracts(t)
+	if err := os.WriteFile(filepath.Join(contractsDir, "todo.object.yaml"), []byte(`service:
+  name: todo-service
+dependencies:
+  services:
+    - name: user-service
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The contract is authoritative: a drifted tsubo list is only a warning
+	tsubo := testTsubo()
+	tsubo.Objects[0].Dependencies = []string{"todo-service"}
+	diags, err := CheckDependencies(tsubo, contractsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		DiagMissingInTsubo + " user-service ":    SeverityWarning,
+		DiagMissingInContract + " todo-service ": SeverityWarning,
+	}
+	got := diagCodes(diags)
+	if len(got) != len(want) {
+		t.Fatalf("diagnostics = %v, want %v", got, want)
+	}
+	for key, severity := range want {
+		if got[key] != severity {
+			t.Errorf("%s: severity %q, want %q", key, got[key], severity)
+		}
+	}
+	if HasErrors(diags) || WarningCount(diags) != 2 {
+		t.Errorf("errors = %d, warnings = %d; want 0, 2", ErrorCount(diags), WarningCount(diags))
+	}
+}
+
+func TestCheckDependenciesUnknownTargets(t *testing.T) {
+	diags, err := CheckDependencies(testTsubo("user-service", "ghost-service", "todo-service"), writeContracts(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := diagCodes(diags)
+	for _, key := range []string{DiagUnknownObject + " ghost-service ", DiagSelfDependency + " todo-service "} {
+		if got[key] != SeverityError {
+			t.Errorf("%s: severity %q, want an error (diagnostics: %v)", key, got[key], got)
+		}
+	}
+}
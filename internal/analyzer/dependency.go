@@ -3,6 +3,7 @@ package analyzer
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/staka121/potter/internal/parser"
 	"github.com/staka121/potter/pkg/types"
@@ -14,12 +15,22 @@ type ObjectWithDeps struct {
 	Contract     string
 	Dependencies []string
 	Port         int
-	IsGateway    bool // True if this is an auto-generated gateway service
+	IsGateway    bool                 // True if this is an auto-generated gateway service
+	Routes       []types.GatewayRoute // Routes of the gateway (gateway only)
 }
 
-// AnalyzeDependencies analyzes all objects and extracts their service dependencies
+// AnalyzeDependencies analyzes all objects and extracts their service dependencies.
+// Dependencies declared as external are provided outside the tsubo and do not
+// affect the implementation order; any other dependency must be an object in
+// the tsubo, otherwise it is reported as unknown (usually a typo).
 func AnalyzeDependencies(tsubo *types.TsuboDefinition, contractsDir string) ([]ObjectWithDeps, error) {
 	var objects []ObjectWithDeps
+	var unknown []string
+
+	objectNames := make([]string, 0, len(tsubo.Objects))
+	for _, objRef := range tsubo.Objects {
+		objectNames = append(objectNames, objRef.Name)
+	}
 
 	for _, objRef := range tsubo.Objects {
 		// Resolve contract file path
@@ -34,6 +45,19 @@ func AnalyzeDependencies(tsubo *types.TsuboDefinition, contractsDir string) ([]O
 		// Extract service dependencies (not database dependencies)
 		var serviceDeps []string
 		for _, dep := range objectDef.Dependencies.Services {
+			if dep.External {
+				continue
+			}
+			if !containsName(objectNames, dep.Name) {
+				msg := fmt.Sprintf("%s depends on unknown service %q", objectDef.Service.Name, dep.Name)
+				if suggestion := SuggestName(dep.Name, objectNames); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				} else {
+					msg += " (declare it with external: true if it is provided outside the tsubo)"
+				}
+				unknown = append(unknown, msg)
+				continue
+			}
 			serviceDeps = append(serviceDeps, dep.Name)
 		}
 
@@ -46,29 +70,120 @@ func AnalyzeDependencies(tsubo *types.TsuboDefinition, contractsDir string) ([]O
 		})
 	}
 
-	// Auto-generate API Gateway if there are multiple services
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown dependencies:\n  %s", strings.Join(unknown, "\n  "))
+	}
+
+	// Generate the API Gateway (by default when there are multiple services)
 	// This implements Tsubo's philosophy: "壺（Tsubo）= single entry point"
-	if len(objects) > 1 {
-		gateway := createGatewayObject(objects)
+	if parser.GatewayEnabled(tsubo) {
+		gateway, err := createGatewayObject(tsubo, contractsDir, objectNames)
+		if err != nil {
+			return nil, err
+		}
 		objects = append(objects, gateway)
 	}
 
 	return objects, nil
 }
 
-// createGatewayObject creates an API Gateway object that depends on all services
-func createGatewayObject(services []ObjectWithDeps) ObjectWithDeps {
-	// Collect all service names as dependencies
-	var allServiceNames []string
-	for _, svc := range services {
-		allServiceNames = append(allServiceNames, svc.Name)
+// createGatewayObject creates the API Gateway object from the tsubo's gateway section.
+// The gateway depends on every service it routes to, plus the services its own
+// contract depends on (e.g. internal-only services used by aggregation routes).
+func createGatewayObject(tsubo *types.TsuboDefinition, contractsDir string, objectNames []string) (ObjectWithDeps, error) {
+	config := tsubo.Gateway
+
+	if containsName(objectNames, config.Name) {
+		return ObjectWithDeps{}, fmt.Errorf("gateway name %q is already used by an object (set gateway.name)", config.Name)
+	}
+	for _, route := range config.Routes {
+		if !containsName(objectNames, route.Service) {
+			return ObjectWithDeps{}, fmt.Errorf("gateway route refers to unknown service %q", route.Service)
+		}
+	}
+
+	routes := parser.GatewayRoutes(tsubo, contractsDir)
+
+	var dependencies []string
+	for _, route := range routes {
+		if !route.Exclude {
+			dependencies = append(dependencies, route.Service)
+		}
+	}
+
+	contractPath := ""
+	if config.Contract != "" {
+		contractPath = filepath.Join(contractsDir, config.Contract)
+		contract, err := parser.ParseObjectFile(contractPath)
+		if err != nil {
+			return ObjectWithDeps{}, fmt.Errorf("failed to parse gateway contract %s: %w", config.Contract, err)
+		}
+		for _, dep := range contract.Dependencies.Services {
+			if dep.External || containsName(dependencies, dep.Name) {
+				continue
+			}
+			if !containsName(objectNames, dep.Name) {
+				return ObjectWithDeps{}, fmt.Errorf("gateway contract depends on unknown service %q", dep.Name)
+			}
+			dependencies = append(dependencies, dep.Name)
+		}
 	}
 
 	return ObjectWithDeps{
-		Name:         "gateway-service",
-		Contract:     "", // Gateway has no contract file - it's auto-generated
-		Dependencies: allServiceNames,
-		Port:         8080, // Standard gateway port
+		Name:         config.Name,
+		Contract:     contractPath, // Empty unless gateway-level endpoints are declared
+		Dependencies: dependencies,
+		Port:         config.Port,
 		IsGateway:    true,
+		Routes:       routes,
+	}, nil
+}
+
+// SuggestName returns the candidate closest to name if it is likely a typo of it, or ""
+func SuggestName(name string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		d := editDistance(name, candidate)
+		if best == "" || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	// Allow roughly one edit per four characters
+	if best == "" || bestDistance > max(1, len(name)/4) {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/state"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// PromptVersion identifies the prompt templates. Bump it whenever a change to
+// the generated prompts should rebuild every object.
+const PromptVersion = "2"
+
+// BuildCache decides which objects need to be implemented again. An object is
+// up to date when its build key matches the one recorded after its last
+// successful build (.potter/builds.json) and the files written then still exist.
+type BuildCache struct {
+	plan          *types.ImplementationPlan
+	model         string
+	state         *state.Manager
+	builds        map[string]types.BuildRecord
+	force         bool
+	forceServices map[string]bool
+	mu            sync.Mutex
+}
+
+// NewBuildCache loads the build records of the plan's tsubo
+func NewBuildCache(plan *types.ImplementationPlan, model string) (*BuildCache, error) {
+	if model == "" {
+		model = DefaultModel
+	}
+
+	mgr := state.NewManager(plan.TsuboFile)
+	builds, err := mgr.LoadBuilds()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuildCache{
+		plan:          plan,
+		model:         model,
+		state:         mgr,
+		builds:        builds,
+		forceServices: make(map[string]bool),
+	}, nil
+}
+
+// SetForce rebuilds every object (all) or the named objects regardless of their build key
+func (c *BuildCache) SetForce(all bool, services []string) error {
+	c.force = all
+	for _, name := range services {
+		if !planHasObject(c.plan, name) {
+			return fmt.Errorf("unknown service %q (not in the plan)", name)
+		}
+		c.forceServices[name] = true
+	}
+	return nil
+}
+
+// Check computes the object's build key and reports whether the object can be skipped
+func (c *BuildCache) Check(obj types.ObjectInWave) (string, bool) {
+	key, err := c.BuildKey(obj)
+	if err != nil {
+		fmt.Printf("   ⚠️  Warning: failed to compute build key: %v\n", err)
+		return "", false
+	}
+
+	if c.force || c.forceServices[obj.Name] {
+		return key, false
+	}
+
+	c.mu.Lock()
+	record, ok := c.builds[obj.Name]
+	c.mu.Unlock()
+	if !ok || record.Key != key {
+		return key, false
+	}
+
+	return key, c.intact(obj.Name, record)
+}
+
+// Record stores the build key of a successfully implemented object
+func (c *BuildCache) Record(name, key string, files []string) error {
+	if key == "" {
+		return nil
+	}
+
+	sorted := append([]string{}, files...)
+	sort.Strings(sorted)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.builds[name] = types.BuildRecord{
+		Key:     key,
+		Model:   c.model,
+		BuiltAt: time.Now(),
+		Files:   sorted,
+	}
+	return c.state.SaveBuilds(c.builds)
+}
+
+// intact reports whether every file written by the recorded build still exists
+func (c *BuildCache) intact(name string, record types.BuildRecord) bool {
+	if len(record.Files) == 0 {
+		return false
+	}
+
+	serviceDir := filepath.Join(c.plan.ImplementationsDir, name)
+	for _, file := range record.Files {
+		if _, err := os.Stat(filepath.Join(serviceDir, file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildKey hashes everything an object's implementation is derived from: the
+// prompt template version, the model, the object's contract (with imported
+// libraries and its architecture file), the contracts of its dependencies,
+// the context files and the tsubo settings that appear in the prompt
+func (c *BuildCache) BuildKey(obj types.ObjectInWave) (string, error) {
+	var parts []string
+	add := func(format string, args ...interface{}) {
+		parts = append(parts, fmt.Sprintf(format, args...))
+	}
+
+	add("prompt=%s", PromptVersion)
+	add("model=%s", c.model)
+	add("object=%s port=%d gateway=%t", obj.Name, obj.Port, obj.IsGateway)
+	add("network=%s/%s", c.plan.Network.Name, c.plan.Network.Driver)
+
+	if obj.Contract != "" {
+		hash, err := c.state.ComputeContractHash(obj.Contract)
+		if err != nil {
+			return "", err
+		}
+		add("contract=%s", hash)
+
+		if archHash := c.architectureHash(obj.Contract); archHash != "" {
+			add("architecture=%s", archHash)
+		}
+	}
+
+	deps := append([]string{}, obj.Dependencies...)
+	sort.Strings(deps)
+	for _, dep := range deps {
+		contract := planContract(c.plan, dep)
+		if contract == "" {
+			add("dependency=%s", dep)
+			continue
+		}
+		hash, err := c.state.ComputeContractHash(contract)
+		if err != nil {
+			return "", err
+		}
+		add("dependency=%s:%s", dep, hash)
+	}
+
+	if len(obj.Routes) > 0 {
+		routes, err := json.Marshal(obj.Routes)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize gateway routes: %w", err)
+		}
+		add("routes=%s", routes)
+	}
+
+	for _, contextFile := range c.plan.ContextFiles {
+		hash, err := c.state.ComputeHash(contextFile)
+		if err != nil {
+			hash = "missing"
+		}
+		add("context=%s:%s", contextFile, hash)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return fmt.Sprintf("%x", sum), nil
+}
+
+// architectureHash hashes the architecture file a contract refers to ("" if none)
+func (c *BuildCache) architectureHash(contractPath string) string {
+	objectDef, err := parser.ParseObjectFile(contractPath)
+	if err != nil || objectDef.Service.Architecture == "" {
+		return ""
+	}
+
+	archPath := filepath.Join(filepath.Dir(contractPath), objectDef.Service.Architecture)
+	hash, err := c.state.ComputeHash(archPath)
+	if err != nil {
+		return "missing"
+	}
+	return hash
+}
+
+// planHasObject reports whether the plan contains an object with the given name
+func planHasObject(plan *types.ImplementationPlan, name string) bool {
+	for _, wave := range plan.Waves {
+		for _, obj := range wave.Objects {
+			if obj.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
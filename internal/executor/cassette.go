@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cassette is one recorded LLM call, stored as <dir>/<key>.json
+type Cassette struct {
+	Key          string            `json:"key"`
+	Model        string            `json:"model"`
+	Messages     []Message         `json:"messages"`
+	Response     string            `json:"response"`
+	Files        map[string]string `json:"files,omitempty"` // write_file tool calls
+	InputTokens  int               `json:"input_tokens"`
+	OutputTokens int               `json:"output_tokens"`
+	RecordedAt   time.Time         `json:"recorded_at"`
+}
+
+// CassetteKey identifies a call by its model and conversation. A single prompt
+// is hashed as is, so cassettes recorded before multi-turn calls still match.
+func CassetteKey(model string, messages []Message) string {
+	if len(messages) == 1 && messages[0].Role == "user" {
+		sum := sha256.Sum256([]byte(model + "\n" + messages[0].Content))
+		return fmt.Sprintf("%x", sum)
+	}
+	conversation, _ := json.Marshal(messages)
+	sum := sha256.Sum256(append([]byte(model+"\n"), conversation...))
+	return fmt.Sprintf("%x", sum)
+}
+
+// Recorder is an Implementer that forwards calls to another Implementer and
+// stores every request/response pair in a cassette directory
+type Recorder struct {
+	client Implementer
+	dir    string
+}
+
+// NewRecorder records the calls made through client into dir
+func NewRecorder(client Implementer, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return &Recorder{client: client, dir: dir}, nil
+}
+
+// Model returns the model of the recorded client
+func (r *Recorder) Model() string {
+	return r.client.Model()
+}
+
+// WritesFiles reports whether the recorded client writes files with tool calls
+func (r *Recorder) WritesFiles() bool {
+	return r.client.WritesFiles()
+}
+
+// Implement calls the recorded client and saves the result as a cassette
+func (r *Recorder) Implement(messages []Message, allowed []string, progress ProgressFunc) (Response, Usage, error) {
+	response, usage, err := r.client.Implement(messages, allowed, progress)
+	if err != nil {
+		return response, usage, err
+	}
+
+	cassette := Cassette{
+		Key:          CassetteKey(r.Model(), messages),
+		Model:        r.Model(),
+		Messages:     messages,
+		Response:     response.Text,
+		Files:        response.Files,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		RecordedAt:   time.Now(),
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return Response{}, Usage{}, fmt.Errorf("failed to serialize cassette: %w", err)
+	}
+	if err := os.WriteFile(cassettePath(r.dir, cassette.Key), data, 0644); err != nil {
+		return Response{}, Usage{}, fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return response, usage, nil
+}
+
+// Replayer is an Implementer that serves recorded cassettes without network
+// access. A call that was never recorded fails instead of reaching a provider.
+type Replayer struct {
+	dir         string
+	model       string
+	writesFiles bool
+}
+
+// NewReplayer serves the cassettes in dir for the given model. writesFiles
+// must match the recorded provider (see Provider.WritesFiles), since it
+// changes the prompts.
+func NewReplayer(dir, model string, writesFiles bool) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cassette path %s is not a directory", dir)
+	}
+	return &Replayer{dir: dir, model: model, writesFiles: writesFiles}, nil
+}
+
+// Model returns the model the cassettes are looked up for
+func (r *Replayer) Model() string {
+	return r.model
+}
+
+// WritesFiles reports whether the recorded provider wrote files with tool calls
+func (r *Replayer) WritesFiles() bool {
+	return r.writesFiles
+}
+
+// Implement returns the recorded response for the model and conversation
+func (r *Replayer) Implement(messages []Message, allowed []string, progress ProgressFunc) (Response, Usage, error) {
+	key := CassetteKey(r.model, messages)
+	path := cassettePath(r.dir, key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Response{}, Usage{}, fmt.Errorf("cassette miss: no recording for model %s and this conversation (expected %s); record it with --record", r.model, path)
+	}
+	if err != nil {
+		return Response{}, Usage{}, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return Response{}, Usage{}, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	progress.tokens(cassette.OutputTokens)
+	return Response{Text: cassette.Response, Files: cassette.Files}, Usage{InputTokens: cassette.InputTokens, OutputTokens: cassette.OutputTokens}, nil
+}
+
+func cassettePath(dir, key string) string {
+	return filepath.Join(dir, key+".json")
+}
@@ -7,20 +7,26 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
 const (
-	anthropicAPIURL = "https://api.anthropic.com/v1/messages"
-	defaultModel    = "claude-sonnet-4-5-20250929"
-	apiVersion      = "2023-06-01"
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	DefaultModel            = "claude-sonnet-4-5-20250929"
+	apiVersion              = "2023-06-01"
+
+	// MaxOutputTokens is the response limit of an implementation request
+	MaxOutputTokens = 8000
 )
 
 // ClaudeClient is a client for the Claude API
 type ClaudeClient struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
 	model      string
+	retry      RetryPolicy
 }
 
 // NewClaudeClient creates a new Claude API client
@@ -34,14 +40,41 @@ func NewClaudeClient() (*ClaudeClient, error) {
 	}
 
 	return &ClaudeClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: DefaultAnthropicBaseURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Minute, // Long timeout for implementation tasks
 		},
-		model: defaultModel,
+		model: DefaultModel,
+		retry: DefaultRetryPolicy,
 	}, nil
 }
 
+// SetModel overrides the model used for requests (empty keeps the default)
+func (c *ClaudeClient) SetModel(model string) {
+	if model != "" {
+		c.model = model
+	}
+}
+
+// SetBaseURL sends requests to another Messages API endpoint, e.g. a corporate
+// proxy (empty keeps the default)
+func (c *ClaudeClient) SetBaseURL(baseURL string) {
+	if baseURL != "" {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// Model returns the model used for requests
+func (c *ClaudeClient) Model() string {
+	return c.model
+}
+
+// SetRetryPolicy changes how transient API failures are retried
+func (c *ClaudeClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
 // Message represents a message in the conversation
 type Message struct {
 	Role    string `json:"role"`
@@ -50,92 +83,275 @@ type Message struct {
 
 // APIRequest represents a request to the Claude API
 type APIRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
+	Model     string       `json:"model"`
+	MaxTokens int          `json:"max_tokens"`
+	Messages  []APIMessage `json:"messages"`
+	Tools     []Tool       `json:"tools,omitempty"`
+	Stream    bool         `json:"stream,omitempty"`
 }
 
-// APIResponse represents a response from the Claude API
-type APIResponse struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Role    string `json:"role"`
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Model        string `json:"model"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+// APIMessage is a Claude API message with structured content
+type APIMessage struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
 }
 
-// ErrorResponse represents an error from the Claude API
-type ErrorResponse struct {
-	Type  string `json:"type"`
+// ContentBlock is a text, tool_use or tool_result block of a message
+type ContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+	IsError   bool            `json:"is_error,omitempty"`    // tool_result
+}
+
+// Tool describes a tool the model may call
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// StreamEvent is one server-sent event of a streaming Claude API response
+type StreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"` // content_block_*
+	Message struct {
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"` // message_start
+	ContentBlock ContentBlock `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"` // content_block_delta, message_delta
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"` // message_delta
 	Error struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
-	} `json:"error"`
+	} `json:"error"` // error
 }
 
-// Implement executes an implementation task using Claude API
-func (c *ClaudeClient) Implement(prompt string) (string, error) {
-	reqBody := APIRequest{
-		Model:     c.model,
-		MaxTokens: 8000, // Sufficient for implementation responses
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-	}
+// Usage reports what a request consumed
+type Usage struct {
+	InputTokens   int
+	OutputTokens  int
+	Attempts      int // API calls made, including retries
+	Continuations int // Extra turns requested after hitting the output token limit
+}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
+// claudeTurn is one streamed assistant message
+type claudeTurn struct {
+	blocks     []ContentBlock
+	stopReason string
+	usage      Usage
+}
 
-	req, err := http.NewRequest("POST", anthropicAPIURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+// WritesFiles reports that files come back as write_file tool calls
+func (c *ClaudeClient) WritesFiles() bool {
+	return true
+}
+
+// Implement executes an implementation task using the streaming Claude API.
+// Files are written with the write_file tool and accumulated across turns:
+// every call gets a tool result, and the conversation continues until the
+// model ends its turn. A message cut off at the output token limit loses only
+// its unfinished call; the model is asked to continue, up to MaxContinuations times.
+func (c *ClaudeClient) Implement(messages []Message, allowed []string, progress ProgressFunc) (Response, Usage, error) {
+	conversation := make([]APIMessage, 0, len(messages)+2)
+	for _, m := range messages {
+		conversation = append(conversation, APIMessage{Role: m.Role, Content: []ContentBlock{{Type: "text", Text: m.Content}}})
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
-	req.Header.Set("anthropic-version", apiVersion)
+	response := Response{Files: make(map[string]string)}
+	var usage Usage
+	for turn := 1; ; turn++ {
+		result, err := c.stream(conversation, progress.after(usage.OutputTokens))
+		usage.InputTokens += result.usage.InputTokens
+		usage.OutputTokens += result.usage.OutputTokens
+		usage.Attempts += result.usage.Attempts
+		if err != nil {
+			return response, usage, err
+		}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		truncated := result.stopReason == "max_tokens"
+		var assistant, results []ContentBlock
+		cutOff := ""
+		for i, block := range result.blocks {
+			switch block.Type {
+			case "text":
+				if strings.TrimSpace(block.Text) == "" {
+					continue
+				}
+				if response.Text != "" {
+					response.Text += "\n\n"
+				}
+				response.Text += block.Text
+				assistant = append(assistant, block)
+			case "tool_use":
+				if (truncated && i == len(result.blocks)-1) || !json.Valid(block.Input) {
+					// Cut off by the token limit: never saved half-written
+					cutOff = "a file"
+					if m := partialPathPattern.FindSubmatch(block.Input); m != nil {
+						cutOff = string(m[1])
+					}
+					continue
+				}
+				assistant = append(assistant, block)
+				results = append(results, writeFile(block, response.Files, allowed))
+			}
+		}
+
+		var note string
+		switch {
+		case result.stopReason == "tool_use" && len(results) > 0:
+			if turn == MaxToolTurns {
+				return response, usage, fmt.Errorf("model was still writing files after %d tool turns", MaxToolTurns)
+			}
+		case truncated:
+			if usage.Continuations == MaxContinuations {
+				return response, usage, fmt.Errorf("%w after %d continuations (%d output tokens)", ErrTruncated, usage.Continuations, usage.OutputTokens)
+			}
+			usage.Continuations++
+			progress.notice("↪ Output token limit reached, requesting continuation %d/%d", usage.Continuations, MaxContinuations)
+			note = "Your response was cut off by the output token limit. Continue with the files you have not written yet."
+			if cutOff != "" {
+				note = fmt.Sprintf("Your response was cut off by the output token limit while writing %s, so that file was NOT saved. "+
+					"Write it again in full (split it into smaller files if it is very large), then continue with the files you have not written yet.", cutOff)
+			}
+		default:
+			return response, usage, nil
+		}
+
+		if note != "" {
+			results = append(results, ContentBlock{Type: "text", Text: note})
+		}
+		if len(assistant) == 0 {
+			// Nothing usable was generated: repeat the last user turn, adding the note once
+			last := &conversation[len(conversation)-1]
+			if n := len(last.Content); n == 0 || last.Content[n-1].Text != note {
+				last.Content = append(last.Content, results...)
+			}
+			continue
+		}
+		conversation = append(conversation,
+			APIMessage{Role: "assistant", Content: assistant},
+			APIMessage{Role: "user", Content: results},
+		)
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
+// stream sends one streaming request and collects the content blocks of the
+// response. A stream that fails with a retryable error is requested again.
+func (c *ClaudeClient) stream(messages []APIMessage, progress ProgressFunc) (claudeTurn, error) {
+	reqBody := APIRequest{
+		Model:     c.model,
+		MaxTokens: MaxOutputTokens, // Per turn; longer responses are continued
+		Messages:  messages,
+		Tools: []Tool{{
+			Name:        WriteFileTool,
+			Description: "Write one complete file of the service implementation. Call it once per file.",
+			InputSchema: writeFileSchema,
+		}},
+		Stream: true,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return claudeTurn{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	var result claudeTurn
+	attempts, err := c.retry.Do(c.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.baseURL+"/v1/messages", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
 		}
-		return "", fmt.Errorf("API error: %s - %s", errResp.Error.Type, errResp.Error.Message)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("x-api-key", c.apiKey)
+		req.Header.Set("anthropic-version", apiVersion)
+		return req, nil
+	}, func(body io.Reader) error {
+		result = claudeTurn{}
+		return readTurn(body, &result, progress)
+	}, progress)
+	result.usage.Attempts = attempts
+	return result, err
+}
 
-	var apiResp APIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+// readTurn reads the event stream of one assistant message into result
+func readTurn(body io.Reader, result *claudeTurn, progress ProgressFunc) error {
+	inputs := make(map[int]*strings.Builder) // tool_use input JSON, by block index
+	estimated := 0
+	err := readEvents(body, func(data []byte) error {
+		var event StreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			result.usage.InputTokens = event.Message.Usage.InputTokens
+			result.usage.OutputTokens = event.Message.Usage.OutputTokens
+		case "content_block_start":
+			for len(result.blocks) <= event.Index {
+				result.blocks = append(result.blocks, ContentBlock{})
+			}
+			block := event.ContentBlock
+			block.Input = nil // Streamed as input_json_delta
+			result.blocks[event.Index] = block
+		case "content_block_delta":
+			if event.Index >= len(result.blocks) {
+				return fmt.Errorf("failed to parse stream: delta for unknown content block %d", event.Index)
+			}
+			chunk := ""
+			switch event.Delta.Type {
+			case "text_delta":
+				result.blocks[event.Index].Text += event.Delta.Text
+				chunk = event.Delta.Text
+			case "input_json_delta":
+				if inputs[event.Index] == nil {
+					inputs[event.Index] = &strings.Builder{}
+				}
+				inputs[event.Index].WriteString(event.Delta.PartialJSON)
+				chunk = event.Delta.PartialJSON
+			}
+			// Exact counts only arrive with message_delta; estimate until then
+			estimated += EstimateTokens(chunk)
+			progress.tokens(max(estimated, result.usage.OutputTokens))
+		case "message_delta":
+			result.stopReason = event.Delta.StopReason
+			result.usage.OutputTokens = event.Usage.OutputTokens
+			progress.tokens(result.usage.OutputTokens)
+		case "error":
+			return &APIError{Kind: streamErrorKind(event.Error.Type), Message: event.Error.Type + " - " + event.Error.Message}
+		}
+		return nil
+	})
+	for i := range result.blocks {
+		if result.blocks[i].Type == "tool_use" {
+			input := "{}"
+			if inputs[i] != nil {
+				input = inputs[i].String()
+			}
+			result.blocks[i].Input = json.RawMessage(input)
+		}
 	}
-
-	if len(apiResp.Content) == 0 {
-		return "", fmt.Errorf("empty response from API")
+	if err != nil {
+		return err
 	}
 
-	return apiResp.Content[0].Text, nil
+	if result.stopReason == "" {
+		return &APIError{Kind: ErrNetwork, Message: "response stream ended before the message was complete"}
+	}
+	return nil
 }
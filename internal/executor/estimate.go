@@ -0,0 +1,198 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/staka121/potter/pkg/state"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// defaultOutputTokens is the projected response size when there is no usage history
+const defaultOutputTokens = 6000
+
+// ObjectEstimate is the projected token usage of one object
+type ObjectEstimate struct {
+	Name         string
+	Wave         int
+	InputTokens  int
+	OutputTokens int
+	FromHistory  bool // output projected from this object's previous runs
+	UpToDate     bool // skipped by the build cache, no API call
+}
+
+// Estimate is the projected token usage and cost of a plan
+type Estimate struct {
+	Model        string
+	Objects      []ObjectEstimate
+	InputTokens  int
+	OutputTokens int
+	HistorySize  int // number of usage records the projection is based on
+	Prices       map[string]types.ModelPrice
+}
+
+// EstimatePlan renders every prompt of the plan (without calling the API or
+// writing files) and projects input and output tokens. Output tokens come from
+// the usage history in .potter/usage.json: the object's average if it was built
+// before, otherwise the average of all objects, otherwise a fixed default.
+// Objects the build cache (optional) reports as up to date cost nothing.
+func EstimatePlan(plan *types.ImplementationPlan, model string, pricing map[string]types.ModelPrice, cache *BuildCache) (*Estimate, error) {
+	if model == "" {
+		model = DefaultModel
+	}
+
+	history, err := state.NewManager(plan.TsuboFile).LoadUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	perObject := make(map[string][]int)
+	var all []int
+	for _, record := range history {
+		perObject[record.Object] = append(perObject[record.Object], record.OutputTokens)
+		all = append(all, record.OutputTokens)
+	}
+
+	generator := NewPromptGenerator(plan)
+	generator.SetDryRun(true)
+
+	estimate := &Estimate{Model: model, HistorySize: len(history), Prices: PriceTable(pricing)}
+	for _, wave := range plan.Waves {
+		for _, obj := range wave.Objects {
+			if cache != nil {
+				if _, upToDate := cache.Check(obj); upToDate {
+					estimate.Objects = append(estimate.Objects, ObjectEstimate{Name: obj.Name, Wave: wave.Wave, UpToDate: true})
+					continue
+				}
+			}
+
+			prompt, err := generator.GeneratePrompt(obj)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate prompt for %s: %w", obj.Name, err)
+			}
+
+			objEstimate := ObjectEstimate{
+				Name:        obj.Name,
+				Wave:        wave.Wave,
+				InputTokens: EstimateTokens(prompt),
+			}
+			switch {
+			case len(perObject[obj.Name]) > 0:
+				objEstimate.OutputTokens = average(perObject[obj.Name])
+				objEstimate.FromHistory = true
+			case len(all) > 0:
+				objEstimate.OutputTokens = average(all)
+			default:
+				objEstimate.OutputTokens = defaultOutputTokens
+			}
+			objEstimate.OutputTokens = min(objEstimate.OutputTokens, MaxOutputTokens)
+
+			estimate.Objects = append(estimate.Objects, objEstimate)
+			estimate.InputTokens += objEstimate.InputTokens
+			estimate.OutputTokens += objEstimate.OutputTokens
+		}
+	}
+
+	return estimate, nil
+}
+
+// EstimateTokens approximates the token count of a text: about four
+// characters per token for ASCII, one token per character otherwise (CJK)
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// Pending returns the number of objects that will call the API
+func (e *Estimate) Pending() int {
+	pending := 0
+	for _, obj := range e.Objects {
+		if !obj.UpToDate {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Cost returns the projected cost for the estimate's model
+func (e *Estimate) Cost() (float64, bool) {
+	price, ok := LookupPrice(e.Prices, e.Model)
+	if !ok {
+		return 0, false
+	}
+	return Cost(price, e.InputTokens, e.OutputTokens), true
+}
+
+// Print writes the per-object, per-wave and total estimate with a price table
+func (e *Estimate) Print(w io.Writer) {
+	fmt.Fprintf(w, "%-28s %5s %12s %12s\n", "Object", "Wave", "Input", "Output")
+
+	waveInput := make(map[int]int)
+	waveOutput := make(map[int]int)
+	var waves []int
+	for _, obj := range e.Objects {
+		marker := ""
+		if obj.FromHistory {
+			marker = " *"
+		}
+		if obj.UpToDate {
+			marker = " (up to date)"
+		}
+		fmt.Fprintf(w, "%-28s %5d %12d %12d%s\n", obj.Name, obj.Wave, obj.InputTokens, obj.OutputTokens, marker)
+
+		if _, seen := waveInput[obj.Wave]; !seen {
+			waves = append(waves, obj.Wave)
+		}
+		waveInput[obj.Wave] += obj.InputTokens
+		waveOutput[obj.Wave] += obj.OutputTokens
+	}
+
+	fmt.Fprintln(w)
+	for _, wave := range waves {
+		fmt.Fprintf(w, "%-28s %5d %12d %12d\n", "wave total", wave, waveInput[wave], waveOutput[wave])
+	}
+	fmt.Fprintf(w, "%-28s %5s %12d %12d\n", "TOTAL", "", e.InputTokens, e.OutputTokens)
+
+	fmt.Fprintln(w)
+	switch {
+	case e.HistorySize > 0:
+		fmt.Fprintf(w, "Output tokens projected from %d previous run(s) (* = this object's own history)\n", e.HistorySize)
+	default:
+		fmt.Fprintf(w, "No usage history yet: assuming %d output tokens per object\n", defaultOutputTokens)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-28s %10s %10s %12s\n", "Model (USD / 1M tokens)", "Input", "Output", "Est. cost")
+	selected, _ := priceEntry(e.Prices, e.Model)
+	for _, model := range sortedModels(e.Prices) {
+		price := e.Prices[model]
+		marker := ""
+		if model == selected {
+			marker = "  <- selected"
+		}
+		fmt.Fprintf(w, "%-28s %10.2f %10.2f %12s%s\n", model, price.Input, price.Output,
+			fmt.Sprintf("$%.2f", Cost(price, e.InputTokens, e.OutputTokens)), marker)
+	}
+
+	if cost, ok := e.Cost(); ok {
+		fmt.Fprintf(w, "\nEstimated cost with %s: $%.2f\n", e.Model, cost)
+	} else {
+		fmt.Fprintf(w, "\nNo price known for %s (add it under pricing: in potter.yaml)\n", e.Model)
+	}
+}
+
+func average(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total / len(values)
+}
@@ -0,0 +1,202 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "gpt-4o"
+)
+
+// OpenAIClient is a client for OpenAI-compatible chat completions APIs. Local
+// model servers such as Ollama (http://localhost:11434/v1) and vLLM speak the
+// same protocol.
+type OpenAIClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	model      string
+	retry      RetryPolicy
+}
+
+// NewOpenAIClient creates a chat completions client for baseURL (empty = OpenAI).
+// OPENAI_API_KEY is required by OpenAI itself and optional for other servers.
+func NewOpenAIClient(baseURL string) (*OpenAIClient, error) {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" && baseURL == DefaultOpenAIBaseURL {
+		return nil, fmt.Errorf("API key not found. Set OPENAI_API_KEY environment variable (or set base_url for a local server)")
+	}
+
+	return &OpenAIClient{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Minute, // Long timeout for implementation tasks
+		},
+		model: DefaultOpenAIModel,
+		retry: DefaultRetryPolicy,
+	}, nil
+}
+
+// SetModel overrides the model used for requests (empty keeps the default)
+func (c *OpenAIClient) SetModel(model string) {
+	if model != "" {
+		c.model = model
+	}
+}
+
+// Model returns the model used for requests
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// SetRetryPolicy changes how transient API failures are retried
+func (c *OpenAIClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// ChatRequest represents a chat completions request
+type ChatRequest struct {
+	Model         string         `json:"model"`
+	MaxTokens     int            `json:"max_tokens"`
+	Messages      []Message      `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions configures a streaming chat completions request
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ChatChunk is one server-sent event of a streaming chat completions response
+type ChatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"` // Final chunk only, when include_usage is set
+}
+
+// WritesFiles reports that files are extracted from the response text: many
+// OpenAI-compatible servers have no tool support
+func (c *OpenAIClient) WritesFiles() bool {
+	return false
+}
+
+// Implement executes an implementation task using the streaming chat
+// completions API. A response cut off at the output token limit is continued
+// with a follow-up user turn, up to MaxContinuations times. Files are
+// extracted from the text afterwards, so allowed is checked by the caller.
+func (c *OpenAIClient) Implement(messages []Message, allowed []string, progress ProgressFunc) (Response, Usage, error) {
+	text, usage, err := continueResponse(progress, func(partial string, outputTokens int) (turnResult, error) {
+		turn := messages
+		if partial != "" {
+			turn = append(turn[:len(turn):len(turn)],
+				Message{Role: "assistant", Content: partial},
+				Message{Role: "user", Content: continuationPrompt},
+			)
+		}
+		result, err := c.stream(turn, progress.after(outputTokens))
+		result.text = partial + result.text
+		return result, err
+	})
+	return Response{Text: text}, usage, err
+}
+
+// stream sends one streaming request and collects the generated text. A
+// stream that fails with a retryable error is requested again.
+func (c *OpenAIClient) stream(messages []Message, progress ProgressFunc) (turnResult, error) {
+	reqBody := ChatRequest{
+		Model:         c.model,
+		MaxTokens:     MaxOutputTokens,
+		Messages:      messages,
+		Stream:        true,
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return turnResult{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var result turnResult
+	attempts, err := c.retry.Do(c.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.baseURL+"/chat/completions", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		return req, nil
+	}, func(body io.Reader) error {
+		result = turnResult{}
+		return readChunks(body, &result, progress)
+	}, progress)
+	result.usage.Attempts = attempts
+	return result, err
+}
+
+// readChunks reads the chunk stream of one completion into result
+func readChunks(body io.Reader, result *turnResult, progress ProgressFunc) error {
+	var text strings.Builder
+	finishReason := ""
+	estimated := 0
+	err := readEvents(body, func(data []byte) error {
+		var chunk ChatChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				estimated += EstimateTokens(choice.Delta.Content)
+				progress.tokens(estimated)
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+		if chunk.Usage != nil {
+			result.usage.InputTokens = chunk.Usage.PromptTokens
+			result.usage.OutputTokens = chunk.Usage.CompletionTokens
+			progress.tokens(result.usage.OutputTokens)
+		}
+		return nil
+	})
+	result.text = text.String()
+	if err != nil {
+		return err
+	}
+
+	if finishReason == "" {
+		return &APIError{Kind: ErrNetwork, Message: "response stream ended before the message was complete"}
+	}
+	if result.usage.OutputTokens == 0 {
+		// Servers that ignore include_usage: fall back to the estimate
+		result.usage.OutputTokens = estimated
+	}
+	result.truncated = finishReason == "length"
+	return nil
+}
@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/staka121/potter/pkg/state"
 	"github.com/staka121/potter/pkg/types"
 )
 
-// LoadPlan loads an implementation plan from a JSON file
+// LoadPlan loads an implementation plan from a JSON file. Relative paths in
+// the plan are relative to the plan file's directory (see SavePlan).
 func LoadPlan(planFile string) (*types.ImplementationPlan, error) {
 	data, err := os.ReadFile(planFile)
 	if err != nil {
@@ -20,5 +25,158 @@ func LoadPlan(planFile string) (*types.ImplementationPlan, error) {
 		return nil, fmt.Errorf("failed to parse plan JSON: %w", err)
 	}
 
+	dir := filepath.Dir(planFile)
+	for _, path := range planPaths(&plan) {
+		if *path != "" && !filepath.IsAbs(filepath.FromSlash(*path)) {
+			*path = filepath.Join(dir, filepath.FromSlash(*path))
+		}
+	}
+
 	return &plan, nil
 }
+
+// SavePlan writes an implementation plan as indented JSON. Relative paths are
+// rewritten relative to the plan file's directory, so that the plan can be
+// used from any working directory; absolute paths are kept.
+func SavePlan(plan *types.ImplementationPlan, planFile string) error {
+	dir, err := filepath.Abs(filepath.Dir(planFile))
+	if err != nil {
+		return fmt.Errorf("failed to resolve plan directory: %w", err)
+	}
+
+	// Rewrite a copy: the caller keeps using its paths
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	var saved types.ImplementationPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	for _, path := range planPaths(&saved) {
+		if *path == "" || filepath.IsAbs(*path) {
+			continue
+		}
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", *path, err)
+		}
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return fmt.Errorf("failed to make %s relative to the plan file: %w", *path, err)
+		}
+		*path = filepath.ToSlash(rel)
+	}
+
+	data, err = json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	if err := os.WriteFile(planFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+
+	return nil
+}
+
+// planPaths returns the file and directory paths of a plan
+func planPaths(plan *types.ImplementationPlan) []*string {
+	paths := []*string{&plan.TsuboFile, &plan.ContractsDir, &plan.ProjectRoot, &plan.ImplementationsDir, &plan.ArtifactsDir}
+	for i := range plan.ContextFiles {
+		paths = append(paths, &plan.ContextFiles[i])
+	}
+	for w := range plan.Waves {
+		for o := range plan.Waves[w].Objects {
+			paths = append(paths, &plan.Waves[w].Objects[o].Contract)
+		}
+	}
+	return paths
+}
+
+// StampPlan records the current hashes of the tsubo file and every contract in the plan
+func StampPlan(plan *types.ImplementationPlan) error {
+	tsuboHash, contractHashes, err := planHashes(plan)
+	if err != nil {
+		return err
+	}
+
+	plan.TsuboHash = tsuboHash
+	plan.ContractHashes = contractHashes
+	return nil
+}
+
+// VerifyPlan checks that the tsubo file and contracts have not changed since the plan was stamped
+func VerifyPlan(plan *types.ImplementationPlan) error {
+	if plan.TsuboHash == "" || plan.ContractHashes == nil {
+		return fmt.Errorf("plan has no contract hashes; regenerate it with 'potter plan %s'", plan.TsuboFile)
+	}
+
+	tsuboHash, contractHashes, err := planHashes(plan)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	if tsuboHash != plan.TsuboHash {
+		stale = append(stale, fmt.Sprintf("%s (tsubo file)", plan.TsuboFile))
+	}
+
+	names := make([]string, 0, len(contractHashes))
+	for name := range contractHashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if planned, ok := plan.ContractHashes[name]; !ok || planned != contractHashes[name] {
+			stale = append(stale, fmt.Sprintf("%s (contract of %s)", planContract(plan, name), name))
+		}
+	}
+
+	if len(stale) > 0 {
+		return fmt.Errorf("plan is stale, changed since it was generated:\n  %s\nReview and regenerate it with 'potter plan %s'",
+			strings.Join(stale, "\n  "), plan.TsuboFile)
+	}
+
+	return nil
+}
+
+// planHashes computes the tsubo hash and the contract hash of every object with a contract
+func planHashes(plan *types.ImplementationPlan) (string, map[string]string, error) {
+	mgr := state.NewManager(plan.TsuboFile)
+
+	tsuboHash, err := mgr.ComputeHash(plan.TsuboFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to hash tsubo file: %w", err)
+	}
+
+	contractHashes := make(map[string]string)
+	for _, wave := range plan.Waves {
+		for _, obj := range wave.Objects {
+			if obj.Contract == "" {
+				continue
+			}
+			hash, err := mgr.ComputeContractHash(obj.Contract)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to hash contract of %s: %w", obj.Name, err)
+			}
+			contractHashes[obj.Name] = hash
+		}
+	}
+
+	return tsuboHash, contractHashes, nil
+}
+
+// planContract returns the contract path of an object in the plan
+func planContract(plan *types.ImplementationPlan, name string) string {
+	for _, wave := range plan.Waves {
+		for _, obj := range wave.Objects {
+			if obj.Name == name {
+				return obj.Contract
+			}
+		}
+	}
+	return name
+}
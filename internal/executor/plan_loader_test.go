@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+func TestSavePlanPathsRelativeToPlanFile(t *testing.T) {
+	root := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Generated from the project root: paths are relative to it
+	plan := &types.ImplementationPlan{
+		TsuboFile:          "contracts/app.tsubo.yaml",
+		ContractsDir:       "contracts",
+		ImplementationsDir: "contracts/implementations",
+		ArtifactsDir:       "/tmp/potter",
+		ContextFiles:       []string{"docs/CONTRACT_DESIGN.md"},
+		Waves: []types.Wave{{Objects: []types.ObjectInWave{
+			{Name: "user-service", Contract: "contracts/user.object.yaml"},
+			{Name: "gateway-service", IsGateway: true},
+		}}},
+	}
+	if err := os.Mkdir("plans", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SavePlan(plan, "plans/plan.json"); err != nil {
+		t.Fatal(err)
+	}
+	if plan.TsuboFile != "contracts/app.tsubo.yaml" || plan.ContextFiles[0] != "docs/CONTRACT_DESIGN.md" || plan.Waves[0].Objects[0].Contract != "contracts/user.object.yaml" {
+		t.Errorf("SavePlan modified the plan: %+v", plan)
+	}
+
+	// Loaded from another directory, the paths point at the same files
+	if err := os.Chdir(filepath.Join(root, "plans")); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPlan("plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for got, want := range map[string]string{
+		loaded.TsuboFile:                    "contracts/app.tsubo.yaml",
+		loaded.ContractsDir:                 "contracts",
+		loaded.ImplementationsDir:           "contracts/implementations",
+		loaded.ContextFiles[0]:              "docs/CONTRACT_DESIGN.md",
+		loaded.Waves[0].Objects[0].Contract: "contracts/user.object.yaml",
+	} {
+		abs, err := filepath.Abs(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if abs != filepath.Join(root, want) {
+			t.Errorf("loaded path %s resolves to %s, want %s", got, abs, filepath.Join(root, want))
+		}
+	}
+	if loaded.ArtifactsDir != "/tmp/potter" {
+		t.Errorf("absolute artifacts dir = %q, want it kept", loaded.ArtifactsDir)
+	}
+	if loaded.Waves[0].Objects[1].Contract != "" {
+		t.Errorf("gateway contract = %q, want it empty", loaded.Waves[0].Objects[1].Contract)
+	}
+}
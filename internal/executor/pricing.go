@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// DefaultPricing is the built-in price table in USD per million tokens, keyed by model prefix.
+// Override or extend it with `pricing:` in potter.yaml.
+var DefaultPricing = map[string]types.ModelPrice{
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-haiku-4":    {Input: 1, Output: 5},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+}
+
+// PriceTable merges the built-in prices with overrides from potter.yaml
+func PriceTable(overrides map[string]types.ModelPrice) map[string]types.ModelPrice {
+	table := make(map[string]types.ModelPrice, len(DefaultPricing)+len(overrides))
+	for model, price := range DefaultPricing {
+		table[model] = price
+	}
+	for model, price := range overrides {
+		table[model] = price
+	}
+	return table
+}
+
+// LookupPrice finds the price of a model: an exact entry wins, then the longest matching prefix
+func LookupPrice(table map[string]types.ModelPrice, model string) (types.ModelPrice, bool) {
+	entry, ok := priceEntry(table, model)
+	if !ok {
+		return types.ModelPrice{}, false
+	}
+	return table[entry], true
+}
+
+// priceEntry returns the key of the table entry that applies to model
+func priceEntry(table map[string]types.ModelPrice, model string) (string, bool) {
+	if _, ok := table[model]; ok {
+		return model, true
+	}
+
+	best := ""
+	for prefix := range table {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return best, best != ""
+}
+
+// Cost returns the USD cost of the given token counts
+func Cost(price types.ModelPrice, inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1_000_000
+}
+
+// sortedModels returns the models of a price table in name order
+func sortedModels(table map[string]types.ModelPrice) []string {
+	models := make([]string, 0, len(table))
+	for model := range table {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
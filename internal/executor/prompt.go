@@ -7,13 +7,16 @@ import (
 	"strings"
 
 	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/database"
 	"github.com/staka121/potter/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
 // PromptGenerator generates implementation prompts for AI agents
 type PromptGenerator struct {
-	plan *types.ImplementationPlan
+	plan       *types.ImplementationPlan
+	dryRun     bool // render prompts without writing CLAUDE.md
+	toolOutput bool // ask for write_file tool calls instead of <create_file> blocks
 }
 
 // NewPromptGenerator creates a new prompt generator
@@ -21,6 +24,17 @@ func NewPromptGenerator(plan *types.ImplementationPlan) *PromptGenerator {
 	return &PromptGenerator{plan: plan}
 }
 
+// SetDryRun renders prompts without writing any files (used for estimates)
+func (pg *PromptGenerator) SetDryRun(dryRun bool) {
+	pg.dryRun = dryRun
+}
+
+// SetToolOutput asks for files as write_file tool calls (for providers with
+// tool support) instead of <create_file> blocks in the response text
+func (pg *PromptGenerator) SetToolOutput(toolOutput bool) {
+	pg.toolOutput = toolOutput
+}
+
 // GeneratePrompt generates a complete implementation prompt for an object
 func (pg *PromptGenerator) GeneratePrompt(obj types.ObjectInWave) (string, error) {
 	// Special handling for gateway service
@@ -70,15 +84,37 @@ func (pg *PromptGenerator) GeneratePrompt(obj types.ObjectInWave) (string, error
 	prompt.WriteString(contractContent)
 	prompt.WriteString("\n```\n\n")
 
+	var objDef types.ObjectDefinition
+	contractParsed := yaml.Unmarshal([]byte(contractContent), &objDef) == nil
+
+	// Include shared type libraries so that $ref into them can be resolved
+	if contractParsed && len(objDef.Imports) > 0 {
+		prompt.WriteString("The contract imports these shared type libraries. ")
+		prompt.WriteString("A `$ref` such as `<file>#/types/<Name>` refers to a type defined in the matching library:\n\n")
+		for _, imp := range objDef.Imports {
+			libContent, err := readFileContent(parser.ImportPath(obj.Contract, imp))
+			if err != nil {
+				return "", fmt.Errorf("failed to read import %s of %s: %w", imp, obj.Contract, err)
+			}
+			prompt.WriteString(fmt.Sprintf("**Type library: %s**\n", imp))
+			prompt.WriteString("```yaml\n")
+			prompt.WriteString(libContent)
+			prompt.WriteString("\n```\n\n")
+		}
+	}
+
 	// If an architecture is specified, write CLAUDE.md to the service directory
 	// and instruct the AI to read it before implementing.
-	var objDef types.ObjectDefinition
-	if err := yaml.Unmarshal([]byte(contractContent), &objDef); err == nil && objDef.Service.Architecture != "" {
+	if contractParsed && objDef.Service.Architecture != "" {
 		archPath := filepath.Join(filepath.Dir(obj.Contract), objDef.Service.Architecture)
 		archDef, err := parser.ParseArchitectureFile(archPath)
 		if err == nil {
 			serviceDir := filepath.Join(pg.plan.ImplementationsDir, obj.Name)
-			if writeErr := writeCLAUDEMD(serviceDir, archDef); writeErr == nil {
+			var writeErr error
+			if !pg.dryRun {
+				writeErr = writeCLAUDEMD(serviceDir, archDef)
+			}
+			if writeErr == nil {
 				claudeMDPath := filepath.Join(serviceDir, "CLAUDE.md")
 				prompt.WriteString("## Architecture Guidelines\n\n")
 				prompt.WriteString(fmt.Sprintf("An architecture definition file has been created at `%s`.\n\n", claudeMDPath))
@@ -127,7 +163,12 @@ func (pg *PromptGenerator) GeneratePrompt(obj types.ObjectInWave) (string, error
 	prompt.WriteString("- Follow Docker First: Everything runs in Docker\n")
 	prompt.WriteString("- Do NOT ask questions during implementation (contract is complete)\n")
 	prompt.WriteString("- Implement exactly what the contract specifies - no more, no less\n")
-	prompt.WriteString("- Use in-memory storage as specified in the contract\n")
+	databases := database.ForService(obj.Name, objDef.Dependencies.Databases)
+	if len(databases) > 0 {
+		prompt.WriteString("- Use the provisioned databases described below for the storage they back\n")
+	} else {
+		prompt.WriteString("- Use in-memory storage as specified in the contract\n")
+	}
 	prompt.WriteString("- All API endpoints must match the contract specification\n")
 	prompt.WriteString("- Handle all edge cases specified in the contract\n")
 	prompt.WriteString("- Use UUIDv4 for IDs\n")
@@ -139,15 +180,8 @@ func (pg *PromptGenerator) GeneratePrompt(obj types.ObjectInWave) (string, error
 	prompt.WriteString(fmt.Sprintf("- In Dockerfile, use EXPOSE %d\n", obj.Port))
 	prompt.WriteString(fmt.Sprintf("- In docker-compose.yml, map port %d:%d\n", obj.Port, obj.Port))
 	prompt.WriteString("- This port is allocated to avoid conflicts with other services\n\n")
-	prompt.WriteString("**Docker network configuration:**\n")
-	prompt.WriteString("- Use network name: tsubo-network\n")
-	prompt.WriteString("- In docker-compose.yml, declare the network as external:\n")
-	prompt.WriteString("  ```yaml\n")
-	prompt.WriteString("  networks:\n")
-	prompt.WriteString("    tsubo-network:\n")
-	prompt.WriteString("      external: true\n")
-	prompt.WriteString("  ```\n")
-	prompt.WriteString("- This allows all services to communicate via the shared network\n\n")
+	pg.writeNetworkSection(&prompt, "- This allows all services to communicate via the shared network\n\n")
+	writeDatabaseSection(&prompt, databases)
 	prompt.WriteString("**Docker Compose format:**\n")
 	prompt.WriteString("- DO NOT include 'version' field in docker-compose.yml (it's obsolete)\n")
 	prompt.WriteString("- Start directly with 'services:' at the top level\n\n")
@@ -156,21 +190,7 @@ func (pg *PromptGenerator) GeneratePrompt(obj types.ObjectInWave) (string, error
 	prompt.WriteString(fmt.Sprintf("**Output directory:** %s\n\n", serviceDir))
 
 	// Output format instructions
-	prompt.WriteString("## Output Format\n\n")
-	prompt.WriteString("**CRITICAL:** You MUST output each file using the following exact format:\n\n")
-	prompt.WriteString("```\n")
-	prompt.WriteString("<create_file>\n")
-	prompt.WriteString("<path>relative/path/to/file.go</path>\n")
-	prompt.WriteString("<content>\n")
-	prompt.WriteString("// File content here\n")
-	prompt.WriteString("</content>\n")
-	prompt.WriteString("</create_file>\n")
-	prompt.WriteString("```\n\n")
-	prompt.WriteString("**Important notes about file paths:**\n")
-	prompt.WriteString(fmt.Sprintf("- All paths should be relative to the service directory\n"))
-	prompt.WriteString("- Example: `main.go` (for top-level files)\n")
-	prompt.WriteString("- Example: `handlers/user.go` (for nested files)\n")
-	prompt.WriteString("- DO NOT include the full path like `poc/implementations/user-service/main.go`\n\n")
+	pg.writeOutputFormat(&prompt, "handlers/user.go", "- DO NOT include the full path like `poc/implementations/user-service/main.go`\n\n")
 	prompt.WriteString("Start implementation now.\n")
 
 	return prompt.String(), nil
@@ -213,11 +233,22 @@ func (pg *PromptGenerator) generateGatewayPrompt(obj types.ObjectInWave) (string
 	prompt.WriteString("## Services to Route\n\n")
 	prompt.WriteString("This gateway must route requests to the following internal services:\n\n")
 
-	// Get all services from previous waves
+	// Get all services from previous waves, split by the gateway's routes
 	allServices := pg.collectAllServices(obj)
+	routes := gatewayRoutes(obj)
+	var routed, internal []types.ObjectInWave
 	for _, svc := range allServices {
+		if routes[svc.Name].Exclude {
+			internal = append(internal, svc)
+		} else {
+			routed = append(routed, svc)
+		}
+	}
+
+	for _, svc := range routed {
 		prompt.WriteString(fmt.Sprintf("### %s\n", svc.Name))
 		prompt.WriteString(fmt.Sprintf("- Internal URL: `http://%s:%d`\n", svc.Name, svc.Port))
+		prompt.WriteString(fmt.Sprintf("- Path prefixes: %s\n", formatPaths(routes[svc.Name].Paths)))
 
 		// Read contract to get API endpoints
 		if svc.Contract != "" {
@@ -231,6 +262,29 @@ func (pg *PromptGenerator) generateGatewayPrompt(obj types.ObjectInWave) (string
 		prompt.WriteString("\n")
 	}
 
+	if len(internal) > 0 {
+		prompt.WriteString("## Internal-Only Services\n\n")
+		prompt.WriteString("The following services are internal-only. The gateway MUST NOT expose any route to them:\n\n")
+		for _, svc := range internal {
+			prompt.WriteString(fmt.Sprintf("- %s (`http://%s:%d`)\n", svc.Name, svc.Name, svc.Port))
+		}
+		prompt.WriteString("\n")
+	}
+
+	// Gateway-level endpoints (e.g. aggregation routes) declared in the gateway's own contract
+	if obj.Contract != "" {
+		contractContent, err := readFileContent(obj.Contract)
+		if err != nil {
+			return "", fmt.Errorf("failed to read gateway contract: %w", err)
+		}
+		prompt.WriteString("## Gateway Endpoints\n\n")
+		prompt.WriteString("Besides proxying, the gateway itself MUST implement the endpoints of this contract.\n")
+		prompt.WriteString("They may call the internal services above (including internal-only ones) and combine their responses.\n\n")
+		prompt.WriteString("```yaml\n")
+		prompt.WriteString(contractContent)
+		prompt.WriteString("\n```\n\n")
+	}
+
 	// Implementation instructions
 	prompt.WriteString("## Implementation Requirements\n\n")
 	prompt.WriteString("**Your task:**\n")
@@ -246,12 +300,17 @@ func (pg *PromptGenerator) generateGatewayPrompt(obj types.ObjectInWave) (string
 	prompt.WriteString("  - README.md (gateway documentation)\n\n")
 
 	prompt.WriteString("**Routing Rules:**\n")
-	for _, svc := range allServices {
-		// Extract API base path from contract if available
-		basePath := "/api/v1"
-		prompt.WriteString(fmt.Sprintf("- Routes starting with `%s` → proxy to `http://%s:%d`\n",
-			basePath, svc.Name, svc.Port))
+	for _, svc := range routed {
+		for _, path := range routes[svc.Name].Paths {
+			prompt.WriteString(fmt.Sprintf("- Routes starting with `%s` → proxy to `http://%s:%d`\n",
+				path, svc.Name, svc.Port))
+		}
 	}
+	prompt.WriteString("- Forward the original request path unchanged (do not strip the prefix)\n")
+	if obj.Contract != "" {
+		prompt.WriteString("- Paths of the gateway endpoints above are handled by the gateway itself\n")
+	}
+	prompt.WriteString("- Any other path returns 404\n")
 	prompt.WriteString("\n")
 
 	prompt.WriteString("**Important principles:**\n")
@@ -268,15 +327,7 @@ func (pg *PromptGenerator) generateGatewayPrompt(obj types.ObjectInWave) (string
 	prompt.WriteString(fmt.Sprintf("- In docker-compose.yml, map port %d:%d\n", obj.Port, obj.Port))
 	prompt.WriteString("- This is the ONLY externally accessible port\n\n")
 
-	prompt.WriteString("**Docker network configuration:**\n")
-	prompt.WriteString("- Use network name: tsubo-network\n")
-	prompt.WriteString("- In docker-compose.yml, declare the network as external:\n")
-	prompt.WriteString("  ```yaml\n")
-	prompt.WriteString("  networks:\n")
-	prompt.WriteString("    tsubo-network:\n")
-	prompt.WriteString("      external: true\n")
-	prompt.WriteString("  ```\n")
-	prompt.WriteString("- This allows the gateway to communicate with all internal services\n\n")
+	pg.writeNetworkSection(&prompt, "- This allows the gateway to communicate with all internal services\n\n")
 
 	prompt.WriteString("**Docker Compose format:**\n")
 	prompt.WriteString("- DO NOT include 'version' field in docker-compose.yml (it's obsolete)\n")
@@ -286,26 +337,86 @@ func (pg *PromptGenerator) generateGatewayPrompt(obj types.ObjectInWave) (string
 	prompt.WriteString(fmt.Sprintf("**Output directory:** %s\n\n", serviceDir))
 
 	// Output format instructions
-	prompt.WriteString("## Output Format\n\n")
-	prompt.WriteString("**CRITICAL:** You MUST output each file using the following exact format:\n\n")
-	prompt.WriteString("```\n")
-	prompt.WriteString("<create_file>\n")
-	prompt.WriteString("<path>relative/path/to/file.go</path>\n")
-	prompt.WriteString("<content>\n")
-	prompt.WriteString("// File content here\n")
-	prompt.WriteString("</content>\n")
-	prompt.WriteString("</create_file>\n")
-	prompt.WriteString("```\n\n")
-	prompt.WriteString("**Important notes about file paths:**\n")
-	prompt.WriteString("- All paths should be relative to the service directory\n")
-	prompt.WriteString("- Example: `main.go` (for top-level files)\n")
-	prompt.WriteString("- Example: `proxy/handler.go` (for nested files)\n")
-	prompt.WriteString("- DO NOT include the full path\n\n")
+	pg.writeOutputFormat(&prompt, "proxy/handler.go", "- DO NOT include the full path\n\n")
 	prompt.WriteString("Start implementation now.\n")
 
 	return prompt.String(), nil
 }
 
+// writeDatabaseSection tells the implementation how to reach the databases Potter provisions
+func writeDatabaseSection(prompt *strings.Builder, databases []database.Instance) {
+	if len(databases) == 0 {
+		return
+	}
+
+	prompt.WriteString("**Databases (provisioned by Potter):**\n")
+	for _, db := range databases {
+		prompt.WriteString(fmt.Sprintf("- `%s` (%s): read the connection string from the `%s` environment variable\n", db.Name, db.Type, db.EnvVar()))
+		prompt.WriteString(fmt.Sprintf("  - Use the Go driver `%s`\n", db.Engine().Driver))
+		if len(db.Tables) > 0 {
+			prompt.WriteString(fmt.Sprintf("  - Holds: %s\n", strings.Join(db.Tables, ", ")))
+		}
+	}
+	prompt.WriteString("- NEVER hardcode hosts or credentials; fail at startup if a variable is missing\n")
+	prompt.WriteString("- The database may still be starting: retry the initial connection for up to 30 seconds\n")
+	prompt.WriteString("- For SQL databases, create the schema on startup if it does not exist (CREATE TABLE IF NOT EXISTS)\n")
+	prompt.WriteString("- In docker-compose.yml, pass each variable through from the .env file written by `potter run`:\n")
+	prompt.WriteString("  ```yaml\n")
+	prompt.WriteString("  environment:\n")
+	for _, db := range databases {
+		prompt.WriteString(fmt.Sprintf("    %s: ${%s}\n", db.EnvVar(), db.EnvVar()))
+	}
+	prompt.WriteString("  ```\n")
+	prompt.WriteString("- Do NOT add database containers to docker-compose.yml (Potter runs them)\n\n")
+}
+
+// writeNetworkSection writes the Docker network instructions using the
+// network declared in the tsubo deployment section
+func (pg *PromptGenerator) writeNetworkSection(prompt *strings.Builder, purpose string) {
+	network := pg.plan.Network
+	if network.Name == "" {
+		network.Name = parser.DefaultNetworkName
+	}
+	if network.Driver == "" {
+		network.Driver = parser.DefaultNetworkDriver
+	}
+
+	prompt.WriteString("**Docker network configuration:**\n")
+	prompt.WriteString(fmt.Sprintf("- Use network name: %s\n", network.Name))
+	prompt.WriteString(fmt.Sprintf("- The network is created by `potter run` with the %s driver\n", network.Driver))
+	prompt.WriteString("- In docker-compose.yml, declare the network as external:\n")
+	prompt.WriteString("  ```yaml\n")
+	prompt.WriteString("  networks:\n")
+	prompt.WriteString(fmt.Sprintf("    %s:\n", network.Name))
+	prompt.WriteString("      external: true\n")
+	prompt.WriteString("  ```\n")
+	prompt.WriteString(purpose)
+}
+
+// gatewayRoutes indexes the gateway's routes by service name. Plans created
+// before routes were recorded fall back to the historical /api/v1 prefix.
+func gatewayRoutes(gateway types.ObjectInWave) map[string]types.GatewayRoute {
+	routes := make(map[string]types.GatewayRoute)
+	for _, route := range gateway.Routes {
+		routes[route.Service] = route
+	}
+	for _, dep := range gateway.Dependencies {
+		if _, ok := routes[dep]; !ok {
+			routes[dep] = types.GatewayRoute{Service: dep, Paths: []string{"/api/v1"}}
+		}
+	}
+	return routes
+}
+
+// formatPaths renders path prefixes as inline code
+func formatPaths(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, path := range paths {
+		quoted[i] = "`" + path + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // collectAllServices collects all services except the gateway itself
 func (pg *PromptGenerator) collectAllServices(gateway types.ObjectInWave) []types.ObjectInWave {
 	var services []types.ObjectInWave
@@ -355,6 +466,15 @@ func writeCLAUDEMD(serviceDir string, archDef *types.ArchitectureDefinition) err
 		content.WriteString("\n")
 	}
 
+	if len(archDef.Architecture.AllowedFiles) > 0 {
+		content.WriteString("## Allowed Files\n\n")
+		content.WriteString("Only files matching these patterns are written; any other file is rejected:\n\n")
+		for _, pattern := range archDef.Architecture.AllowedFiles {
+			content.WriteString(fmt.Sprintf("- `%s`\n", pattern))
+		}
+		content.WriteString("\n")
+	}
+
 	if archDef.Architecture.Notes != "" {
 		content.WriteString("## Additional Notes\n\n")
 		content.WriteString(archDef.Architecture.Notes)
@@ -373,3 +493,28 @@ func readFileContent(filePath string) (string, error) {
 	}
 	return string(data), nil
 }
+
+// writeOutputFormat explains how files are returned and how their paths are written
+func (pg *PromptGenerator) writeOutputFormat(prompt *strings.Builder, nestedExample, fullPathNote string) {
+	prompt.WriteString("## Output Format\n\n")
+	if pg.toolOutput {
+		prompt.WriteString(fmt.Sprintf("**CRITICAL:** Write every file with the `%s` tool, one call per file with its complete content.\n", WriteFileTool))
+		prompt.WriteString(fmt.Sprintf("- You may call `%s` several times in one response\n", WriteFileTool))
+		prompt.WriteString(fmt.Sprintf("- Only `%s` calls are saved: do not paste file contents into your text\n\n", WriteFileTool))
+	} else {
+		prompt.WriteString("**CRITICAL:** You MUST output each file using the following exact format:\n\n")
+		prompt.WriteString("```\n")
+		prompt.WriteString("<create_file>\n")
+		prompt.WriteString("<path>relative/path/to/file.go</path>\n")
+		prompt.WriteString("<content>\n")
+		prompt.WriteString("// File content here\n")
+		prompt.WriteString("</content>\n")
+		prompt.WriteString("</create_file>\n")
+		prompt.WriteString("```\n\n")
+	}
+	prompt.WriteString("**Important notes about file paths:**\n")
+	prompt.WriteString("- All paths should be relative to the service directory\n")
+	prompt.WriteString("- Example: `main.go` (for top-level files)\n")
+	prompt.WriteString(fmt.Sprintf("- Example: `%s` (for nested files)\n", nestedExample))
+	prompt.WriteString(fullPathNote)
+}
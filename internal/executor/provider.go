@@ -0,0 +1,92 @@
+package executor
+
+import "fmt"
+
+// Implementer generates a service implementation from a prompt. The runner,
+// the migration executor and refactor depend only on this interface.
+type Implementer interface {
+	// Implement sends a conversation (starting with the user's prompt and
+	// ending with a user turn) and returns the model's complete response,
+	// reporting generated output tokens to progress as they arrive. Files
+	// written with tool calls must match the allowed globs (nil = any path).
+	Implement(messages []Message, allowed []string, progress ProgressFunc) (Response, Usage, error)
+	// Model returns the model used for requests
+	Model() string
+	// WritesFiles reports whether files come back as write_file tool calls;
+	// otherwise they are extracted from the response text
+	WritesFiles() bool
+}
+
+// Provider selects the LLM API used for implementation
+type Provider string
+
+const (
+	// ProviderClaude uses the Anthropic Messages API
+	ProviderClaude Provider = "claude"
+	// ProviderOpenAI uses an OpenAI-compatible chat completions API (OpenAI, Ollama, vLLM, ...)
+	ProviderOpenAI Provider = "openai"
+)
+
+// ParseProvider validates a provider name (empty selects the default)
+func ParseProvider(name string) (Provider, error) {
+	switch Provider(name) {
+	case "":
+		return ProviderClaude, nil
+	case ProviderClaude, ProviderOpenAI:
+		return Provider(name), nil
+	}
+	return "", fmt.Errorf("unknown provider %q (expected claude or openai)", name)
+}
+
+// DefaultModel returns the model the provider uses when none is configured
+func (p Provider) DefaultModel() string {
+	if p == ProviderOpenAI {
+		return DefaultOpenAIModel
+	}
+	return DefaultModel
+}
+
+// WritesFiles reports whether the provider's client writes files with tool calls
+func (p Provider) WritesFiles() bool {
+	return p != ProviderOpenAI
+}
+
+// ProviderConfig selects the LLM provider and where to reach it
+type ProviderConfig struct {
+	Provider Provider
+	BaseURL  string // empty = the provider's public API
+	Model    string // empty = the provider's default model
+
+	// MaxAttempts limits the API calls per request, including retries of
+	// transient failures (0 = DefaultRetryPolicy)
+	MaxAttempts int
+}
+
+// NewImplementer creates the client of the configured provider
+func NewImplementer(config ProviderConfig) (Implementer, error) {
+	retry := DefaultRetryPolicy
+	if config.MaxAttempts > 0 {
+		retry.MaxAttempts = config.MaxAttempts
+	}
+
+	switch config.Provider {
+	case ProviderOpenAI:
+		client, err := NewOpenAIClient(config.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		client.SetModel(config.Model)
+		client.SetRetryPolicy(retry)
+		return client, nil
+	case ProviderClaude, "":
+		client, err := NewClaudeClient()
+		if err != nil {
+			return nil, err
+		}
+		client.SetBaseURL(config.BaseURL)
+		client.SetModel(config.Model)
+		client.SetRetryPolicy(retry)
+		return client, nil
+	}
+	return nil, fmt.Errorf("unknown provider %q (expected claude or openai)", config.Provider)
+}
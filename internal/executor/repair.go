@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// DefaultRepairRounds is used unless repair_rounds is set in potter.yaml
+const DefaultRepairRounds = 2
+
+const (
+	checkTimeout   = 5 * time.Minute
+	maxCheckOutput = 16 * 1024 // Bytes of check output sent back to the model
+)
+
+// RepairPolicy checks generated code and asks the model to fix failures
+type RepairPolicy struct {
+	Rounds int  // Follow-up turns allowed to fix failing checks (0 = report failures only)
+	Tests  bool // Also run the generated tests
+
+	// Offline runs the checks without network access: modules are never
+	// downloaded and go.mod and go.sum are never rewritten (used by replays)
+	Offline bool
+}
+
+// NewRepairPolicy returns the repair policy configured in potter.yaml, or nil
+// if checks are disabled
+func NewRepairPolicy(config *types.ProjectConfig) *RepairPolicy {
+	if config.SkipChecks {
+		return nil
+	}
+	rounds := config.RepairRounds
+	if rounds == 0 {
+		rounds = DefaultRepairRounds
+	}
+	return &RepairPolicy{Rounds: max(rounds, 0), Tests: config.RunTests}
+}
+
+// CheckFailure is the output of the first check that failed
+type CheckFailure struct {
+	Command string
+	Output  string
+}
+
+// Commands returns the checks run in a service directory, in order
+func (p *RepairPolicy) Commands() [][]string {
+	commands := [][]string{
+		{"go", "build", "-o", os.DevNull, "./..."}, // Leave no binary behind in the service directory
+		{"go", "vet", "./..."},
+	}
+	if p.Tests {
+		commands = append(commands, []string{"go", "test", "./..."})
+	}
+	return commands
+}
+
+// CheckList lists the check commands for display
+func (p *RepairPolicy) CheckList() string {
+	names := make([]string, 0, 3)
+	for _, args := range p.Commands() {
+		names = append(names, strings.Join(args, " "))
+	}
+	if p.Offline {
+		return strings.Join(names, ", ") + " (offline)"
+	}
+	return strings.Join(names, ", ")
+}
+
+// env returns the Go environment of the checks
+func (p *RepairPolicy) env() []string {
+	if p.Offline {
+		// Only the module cache is used; a missing module fails the check
+		return []string{"GOFLAGS=-mod=readonly", "GOPROXY=off"}
+	}
+	// Resolve the generated go.mod's requirements instead of failing on go.sum
+	return []string{"GOFLAGS=-mod=mod"}
+}
+
+// Check runs the checks in serviceDir and returns the first failure (nil if
+// all pass). Services without a go.mod, or machines without a Go toolchain,
+// are not checked; skipped explains why.
+func (p *RepairPolicy) Check(serviceDir string) (failure *CheckFailure, skipped string) {
+	if _, err := os.Stat(filepath.Join(serviceDir, "go.mod")); err != nil {
+		return nil, "no go.mod"
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, "go toolchain not found"
+	}
+
+	for _, args := range p.Commands() {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = serviceDir
+		cmd.Env = append(os.Environ(), p.env()...)
+		output, err := cmd.CombinedOutput()
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancel()
+		if err != nil {
+			out := strings.TrimSpace(string(output))
+			if timedOut {
+				out += fmt.Sprintf("\n(timed out after %s)", checkTimeout)
+			} else if out == "" {
+				out = err.Error()
+			}
+			return &CheckFailure{Command: strings.Join(args, " "), Output: out}, ""
+		}
+	}
+	return nil, ""
+}
+
+// repairPrompt asks the model to fix the files that failed a check, with
+// write_file calls if tools is set
+func repairPrompt(failure *CheckFailure, files map[string]string, round, rounds int, tools bool) string {
+	output := failure.Output
+	if len(output) > maxCheckOutput {
+		output = output[:maxCheckOutput] + "\n... (output truncated)"
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("# Repair Round %d/%d\n\n", round, rounds))
+	prompt.WriteString(fmt.Sprintf("Your files were saved to the service directory, but `%s` failed there:\n\n", failure.Command))
+	prompt.WriteString("```\n")
+	prompt.WriteString(output)
+	prompt.WriteString("\n```\n\n")
+
+	prompt.WriteString("## Current files\n\n")
+	prompt.WriteString(formatFiles(files))
+
+	prompt.WriteString("## Your task\n\n")
+	prompt.WriteString("- Fix the errors above without changing the behavior required by the contract\n")
+	if tools {
+		prompt.WriteString(fmt.Sprintf("- Call `%s` ONLY for the files you change, each one complete (not a diff)\n", WriteFileTool))
+	} else {
+		prompt.WriteString("- Output ONLY the files you change, each one complete (not a diff)\n")
+		prompt.WriteString("- Use the same <create_file> format as before\n")
+	}
+	prompt.WriteString("- Files you do not output are kept as they are\n")
+	return prompt.String()
+}
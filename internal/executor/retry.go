@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies a failed API call
+type ErrorKind string
+
+const (
+	ErrRateLimited ErrorKind = "rate_limited" // 429
+	ErrOverloaded  ErrorKind = "overloaded"   // 529
+	ErrServer      ErrorKind = "server_error" // other 5xx
+	ErrTimeout     ErrorKind = "timeout"      // client timeout or 408
+	ErrNetwork     ErrorKind = "network"      // connection failures
+	ErrClient      ErrorKind = "client_error" // other 4xx (bad request, auth, ...): not retried
+)
+
+// APIError is a failed API call
+type APIError struct {
+	Kind       ErrorKind
+	StatusCode int           // 0 for timeouts and network errors
+	Message    string        // Provider error message or transport error
+	RetryAfter time.Duration // Delay requested by the retry-after header (0 if none)
+	Err        error         // Transport error (timeouts and network errors)
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("API error: %s (status %d): %s", e.Kind, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error: %s: %s", e.Kind, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the call may succeed if sent again
+func (e *APIError) Retryable() bool {
+	return e.Kind != ErrClient
+}
+
+// RetryPolicy retries transient API failures with exponential backoff and jitter
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first (1 = no retries)
+	BaseDelay   time.Duration // Delay before the second attempt, doubled for each further attempt
+	MaxDelay    time.Duration // Upper bound of the backoff (a retry-after header may ask for longer)
+
+	// Sleep waits between attempts (nil = time.Sleep); replaceable in tests
+	Sleep func(time.Duration)
+}
+
+// DefaultRetryPolicy is used unless max_attempts is set in potter.yaml
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    60 * time.Second,
+}
+
+// Do sends the request built by newRequest and passes the body of the
+// successful response to read (e.g. to consume a stream), until it succeeds,
+// fails with a non-retryable error or runs out of attempts. A retryable
+// *APIError from read, such as an error event or a dropped connection in the
+// middle of a stream, sends the request again, so read must start over on
+// every call. Retries are announced to progress. It returns the number of
+// attempts made.
+func (p RetryPolicy) Do(client *http.Client, newRequest func() (*http.Request, error), read func(body io.Reader) error, progress ProgressFunc) (int, error) {
+	maxAttempts := max(p.MaxAttempts, 1)
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := exchange(client, newRequest, read)
+		if err == nil {
+			return attempt, nil
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.Retryable() {
+			return attempt, err
+		}
+		if attempt >= maxAttempts {
+			if maxAttempts > 1 {
+				return attempt, fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+			}
+			return attempt, err
+		}
+
+		delay := p.Backoff(attempt)
+		if apiErr.RetryAfter > 0 {
+			delay = apiErr.RetryAfter
+		}
+		progress.notice("⏳ %s, retrying in %s (attempt %d/%d)", apiErr.Kind, delay.Round(time.Millisecond), attempt+1, maxAttempts)
+		sleep(delay)
+	}
+}
+
+// exchange makes one attempt: it sends the request and reads the response
+func exchange(client *http.Client, newRequest func() (*http.Request, error), read func(body io.Reader) error) error {
+	resp, err := send(client, newRequest)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return read(resp.Body)
+}
+
+// Backoff returns the delay after the given failed attempt: BaseDelay doubled
+// per attempt, capped at MaxDelay, with half of it randomized ("equal jitter")
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// send makes one attempt and classifies its failure. The body of a successful
+// response is left unread.
+func send(client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	req, err := newRequest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		kind := ErrNetwork
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			kind = ErrTimeout
+		}
+		return nil, &APIError{Kind: kind, Message: err.Error(), Err: err}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &APIError{Kind: ErrNetwork, Message: fmt.Sprintf("failed to read response: %v", err), Err: err}
+	}
+
+	return nil, &APIError{
+		Kind:       classifyStatus(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Message:    errorMessage(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("retry-after")),
+	}
+}
+
+// classifyStatus maps an HTTP status to an error kind
+func classifyStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == 529:
+		return ErrOverloaded
+	case status == http.StatusRequestTimeout:
+		return ErrTimeout
+	case status >= 500:
+		return ErrServer
+	}
+	return ErrClient
+}
+
+// errorMessage extracts the message of an error response. Anthropic and
+// OpenAI-compatible APIs both use {"error": {"type": ..., "message": ...}}.
+func errorMessage(body []byte) string {
+	var errResp struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Message == "" {
+		return strings.TrimSpace(string(body))
+	}
+	if errResp.Error.Type == "" {
+		return errResp.Error.Message
+	}
+	return errResp.Error.Type + " - " + errResp.Error.Message
+}
+
+// parseRetryAfter reads a retry-after header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// retryResponse is a canned response of retryServer
+type retryResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// retryServer answers each request with the next of responses (the last one is
+// repeated) and counts the requests
+func retryServer(t *testing.T, responses ...retryResponse) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		resp := responses[min(n, len(responses))-1]
+		if resp.retryAfter != "" {
+			w.Header().Set("retry-after", resp.retryAfter)
+		}
+		w.WriteHeader(resp.status)
+		fmt.Fprint(w, resp.body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// doRetry runs policy against server, recording the delays instead of sleeping
+func doRetry(t *testing.T, policy RetryPolicy, server *httptest.Server, read func(io.Reader) error) (int, []time.Duration, []string, error) {
+	t.Helper()
+	var delays []time.Duration
+	var notices []string
+	policy.Sleep = func(d time.Duration) { delays = append(delays, d) }
+	if read == nil {
+		read = func(io.Reader) error { return nil }
+	}
+
+	attempts, err := policy.Do(server.Client(), func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader("{}"))
+	}, read, func(p Progress) {
+		if p.Notice != "" {
+			notices = append(notices, p.Notice)
+		}
+	})
+	return attempts, delays, notices, err
+}
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+func TestRetryPolicyRetriesRateLimitAndOverload(t *testing.T) {
+	server, requests := retryServer(t,
+		retryResponse{status: http.StatusTooManyRequests, body: `{"error":{"type":"rate_limit_error","message":"slow down"}}`},
+		retryResponse{status: 529, body: `{"error":{"type":"overloaded_error","message":"Overloaded"}}`},
+		retryResponse{status: http.StatusOK, body: "ok"},
+	)
+
+	var body string
+	attempts, delays, notices, err := doRetry(t, testRetryPolicy, server, func(r io.Reader) error {
+		b, err := io.ReadAll(r)
+		body = string(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || *requests != 3 {
+		t.Errorf("attempts = %d, requests = %d; want 3", attempts, *requests)
+	}
+	if body != "ok" {
+		t.Errorf("read body %q, want the successful response", body)
+	}
+	if len(delays) != 2 {
+		t.Errorf("slept %d times, want 2", len(delays))
+	}
+	if len(notices) != 2 || !strings.Contains(notices[0], string(ErrRateLimited)) || !strings.Contains(notices[1], string(ErrOverloaded)) {
+		t.Errorf("notices = %q, want a rate limit and an overload retry", notices)
+	}
+}
+
+func TestRetryPolicyRetryAfter(t *testing.T) {
+	server, _ := retryServer(t,
+		retryResponse{status: http.StatusTooManyRequests, retryAfter: "7"},
+		retryResponse{status: http.StatusTooManyRequests, retryAfter: "0.5"},
+		retryResponse{status: http.StatusOK},
+	)
+
+	_, delays, _, err := doRetry(t, testRetryPolicy, server, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header wins over the backoff, even beyond MaxDelay
+	want := []time.Duration{7 * time.Second, 500 * time.Millisecond}
+	if fmt.Sprint(delays) != fmt.Sprint(want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"3", 3 * time.Second, 3 * time.Second},
+		{" 1.5 ", 1500 * time.Millisecond, 1500 * time.Millisecond},
+		{"0", 0, 0},
+		{"-2", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat), 28 * time.Second, 30 * time.Second},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0}, // In the past
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 2 * time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		attempt int
+		delay   time.Duration // Before jitter
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second}, // Capped
+		{20, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.Backoff(tt.attempt); got < tt.delay/2 || got > tt.delay {
+				t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.delay/2, tt.delay)
+			}
+		}
+	}
+
+	if got := (RetryPolicy{}).Backoff(1); got != 0 {
+		t.Errorf("Backoff without a base delay = %v, want 0", got)
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	server, requests := retryServer(t, retryResponse{status: http.StatusServiceUnavailable, body: "unavailable"})
+
+	attempts, delays, _, err := doRetry(t, testRetryPolicy, server, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind != ErrServer {
+		t.Fatalf("err = %v, want a server error", err)
+	}
+	if !strings.Contains(err.Error(), "gave up after 4 attempts") {
+		t.Errorf("err = %v, want it to say it gave up", err)
+	}
+	if attempts != 4 || *requests != 4 || len(delays) != 3 {
+		t.Errorf("attempts = %d, requests = %d, sleeps = %d; want 4, 4, 3", attempts, *requests, len(delays))
+	}
+
+	// A single attempt is not retried and not reported as giving up
+	server, requests = retryServer(t, retryResponse{status: http.StatusServiceUnavailable})
+	_, _, _, err = doRetry(t, RetryPolicy{MaxAttempts: 1}, server, nil)
+	if err == nil || strings.Contains(err.Error(), "gave up") || *requests != 1 {
+		t.Errorf("single attempt: err = %v, requests = %d", err, *requests)
+	}
+}
+
+func TestRetryPolicyClientErrorNotRetried(t *testing.T) {
+	server, requests := retryServer(t, retryResponse{status: http.StatusUnauthorized, body: `{"error":{"type":"authentication_error","message":"invalid x-api-key"}}`})
+
+	_, delays, _, err := doRetry(t, testRetryPolicy, server, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind != ErrClient {
+		t.Fatalf("err = %v, want a client error", err)
+	}
+	if apiErr.Message != "authentication_error - invalid x-api-key" {
+		t.Errorf("message = %q", apiErr.Message)
+	}
+	if *requests != 1 || len(delays) != 0 {
+		t.Errorf("requests = %d, sleeps = %d; want no retry", *requests, len(delays))
+	}
+}
+
+func TestRetryPolicyRetriesStreamErrors(t *testing.T) {
+	server, requests := retryServer(t, retryResponse{status: http.StatusOK, body: "stream"})
+
+	reads := 0
+	attempts, _, _, err := doRetry(t, testRetryPolicy, server, func(io.Reader) error {
+		reads++
+		switch reads {
+		case 1:
+			return &APIError{Kind: ErrOverloaded, Message: "overloaded_error event"}
+		case 2:
+			return &APIError{Kind: ErrNetwork, Message: "stream ended before message_stop"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || *requests != 3 {
+		t.Errorf("attempts = %d, requests = %d; want the request sent again for each stream error", attempts, *requests)
+	}
+
+	// Other read errors are returned as they are
+	readErr := errors.New("malformed event")
+	_, _, _, err = doRetry(t, testRetryPolicy, server, func(io.Reader) error { return readErr })
+	if !errors.Is(err, readErr) {
+		t.Errorf("err = %v, want the read error", err)
+	}
+}
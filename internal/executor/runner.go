@@ -7,60 +7,114 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/state"
 	"github.com/staka121/potter/pkg/types"
 )
 
 // ExecutionResult represents the result of implementing a service
 type ExecutionResult struct {
-	ObjectName   string
-	Success      bool
-	Error        error
-	Response     string
-	Duration     time.Duration
-	InputTokens  int
-	OutputTokens int
+	ObjectName    string
+	Success       bool
+	Error         error
+	Response      string
+	Duration      time.Duration
+	InputTokens   int
+	OutputTokens  int
+	Skipped       bool // Up to date: build key unchanged since the last successful build
+	Attempts      int  // API calls made, including retries of transient failures
+	Continuations int  // Extra turns after hitting the output token limit
+	RepairRounds  int  // Follow-up turns that fixed failing build/vet/test checks
 }
 
 // Runner executes implementation tasks
 type Runner struct {
-	client      *ClaudeClient
+	client      Implementer
 	generator   *PromptGenerator
 	plan        *types.ImplementationPlan
-	concurrency int    // 0 = unlimited
-	tempDir     string // temporary directory for this run
+	concurrency int      // 0 = unlimited
+	schedule    Schedule // dag (default) or waves
+	tempDir     string   // temporary directory for this run
+	cache       *BuildCache
+	repair      *RepairPolicy // nil = generated code is not checked
 }
 
-// NewRunner creates a new execution runner
-func NewRunner(plan *types.ImplementationPlan) (*Runner, error) {
-	client, err := NewClaudeClient()
+// NewRunner creates a new execution runner that implements objects with client
+func NewRunner(plan *types.ImplementationPlan, client Implementer) (*Runner, error) {
+	tempDir, err := NewRunDir(plan)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create timestamped temp directory
-	// Format: /tmp/potter/{app-name}/yyyymmddhhmmss
-	timestamp := time.Now().Format("20060102150405")
-	tempDir := filepath.Join("/tmp", "potter", plan.Tsubo, timestamp)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	// Clean up after saves interrupted by an earlier run
+	recovered, err := recoverStaging(plan.ImplementationsDir)
+	for _, action := range recovered {
+		fmt.Printf("♻️  Implementations: %s\n", action)
+	}
+	if err != nil {
+		return nil, err
 	}
 
+	generator := NewPromptGenerator(plan)
+	generator.SetToolOutput(client.WritesFiles())
+
 	return &Runner{
 		client:      client,
-		generator:   NewPromptGenerator(plan),
+		generator:   generator,
 		plan:        plan,
 		concurrency: 0, // unlimited by default
+		schedule:    ScheduleDAG,
 		tempDir:     tempDir,
 	}, nil
 }
 
+// NewRunDir creates the timestamped directory where a run's prompts and responses are saved
+// Format: {artifacts-dir}/{app-name}/yyyymmddhhmmss (artifacts dir defaults to /tmp/potter)
+func NewRunDir(plan *types.ImplementationPlan) (string, error) {
+	artifactsDir := plan.ArtifactsDir
+	if artifactsDir == "" {
+		artifactsDir = parser.DefaultArtifactsDir
+	}
+
+	timestamp := time.Now().Format("20060102150405")
+	runDir := filepath.Join(artifactsDir, plan.Tsubo, timestamp)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	return runDir, nil
+}
+
 // SetConcurrency sets the maximum number of parallel executions
 func (r *Runner) SetConcurrency(n int) {
 	r.concurrency = n
 }
 
+// SetSchedule selects dependency-driven (dag) or wave-barrier (waves) scheduling
+func (r *Runner) SetSchedule(schedule Schedule) {
+	r.schedule = schedule
+}
+
+// SetBuildCache enables incremental builds: objects the cache reports as up to
+// date are skipped, and successful builds are recorded in it
+func (r *Runner) SetBuildCache(cache *BuildCache) {
+	r.cache = cache
+}
+
+// SetRepair checks generated code after it is saved and lets the model fix
+// failures (nil disables the checks). When replaying cassettes, the checks run
+// offline so that a replay never touches the network.
+func (r *Runner) SetRepair(policy *RepairPolicy) {
+	if _, replaying := r.client.(*Replayer); replaying && policy != nil {
+		offline := *policy
+		offline.Offline = true
+		policy = &offline
+	}
+	r.repair = policy
+}
+
 // GetTempDir returns the temporary directory for this run
 func (r *Runner) GetTempDir() string {
 	return r.tempDir
@@ -74,6 +128,7 @@ func (r *Runner) ExecuteSingle(objectName string) (*ExecutionResult, error) {
 				var mu sync.Mutex
 				completedObjects := 0
 				result, err := r.executeObject(obj, &completedObjects, 1, &mu)
+				r.recordUsage([]ExecutionResult{result})
 				return &result, err
 			}
 		}
@@ -81,8 +136,45 @@ func (r *Runner) ExecuteSingle(objectName string) (*ExecutionResult, error) {
 	return nil, fmt.Errorf("object %s not found in implementation plan", objectName)
 }
 
-// ExecuteAll executes all waves in the implementation plan
+// ExecuteAll executes every object in the implementation plan using the runner's schedule
 func (r *Runner) ExecuteAll() ([]ExecutionResult, error) {
+	var results []ExecutionResult
+	var err error
+	if r.schedule == ScheduleWaves {
+		results, err = r.executeWaves()
+	} else {
+		results, err = r.executeDAG()
+	}
+	r.recordUsage(results)
+	return results, err
+}
+
+// recordUsage appends the tokens of every API call to the usage history used for estimates
+func (r *Runner) recordUsage(results []ExecutionResult) {
+	var records []types.UsageRecord
+	for _, result := range results {
+		if result.InputTokens == 0 && result.OutputTokens == 0 {
+			continue
+		}
+		records = append(records, types.UsageRecord{
+			Timestamp:    time.Now(),
+			Object:       result.ObjectName,
+			Model:        r.client.Model(),
+			InputTokens:  result.InputTokens,
+			OutputTokens: result.OutputTokens,
+		})
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	if err := state.NewManager(r.plan.TsuboFile).AppendUsage(records...); err != nil {
+		fmt.Printf("⚠️  Warning: failed to record token usage: %v\n", err)
+	}
+}
+
+// executeWaves executes the plan wave by wave, waiting for each wave to finish
+func (r *Runner) executeWaves() ([]ExecutionResult, error) {
 	var allResults []ExecutionResult
 	totalWaves := len(r.plan.Waves)
 	totalObjects := 0
@@ -220,6 +312,20 @@ func (r *Runner) executeObject(obj types.ObjectInWave, completedObjects *int, to
 		fmt.Printf("   Dependencies: %v\n", obj.Dependencies)
 	}
 
+	// Skip objects whose inputs are unchanged since their last successful build
+	buildKey := ""
+	if r.cache != nil {
+		var upToDate bool
+		buildKey, upToDate = r.cache.Check(obj)
+		if upToDate {
+			fmt.Printf("   ♻️  Up to date (build key unchanged), skipping\n")
+			result.Success = true
+			result.Skipped = true
+			result.Duration = time.Since(start)
+			return result, nil
+		}
+	}
+
 	// Generate prompt
 	fmt.Printf("   ⏳ Generating prompt...\n")
 	prompt, err := r.generator.GeneratePrompt(obj)
@@ -233,10 +339,74 @@ func (r *Runner) executeObject(obj types.ObjectInWave, completedObjects *int, to
 		fmt.Printf("   ⚠️  Warning: failed to save prompt file: %v\n", err)
 	}
 
-	// Execute with Claude API
-	fmt.Printf("   🤖 Calling Claude API (this may take a while)...\n")
+	// Files may only be written inside the service directory, and only where
+	// the architecture's allowlist permits
+	allowed, err := allowedFiles(obj.Contract)
+	if err != nil {
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	// Execute with the LLM provider
+	messages := []Message{{Role: "user", Content: prompt}}
+	response, files, err := r.generate(obj, messages, allowed, "", &result)
+	if err != nil {
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	// Drop files with unsafe paths or outside the allowlist (text responses
+	// are only checked here)
+	files = acceptFiles(files, allowed)
+	if len(files) == 0 {
+		result.Duration = time.Since(start)
+		return result, fmt.Errorf("no files left to write after rejecting unsafe or disallowed paths")
+	}
+
+	// Replace the implementation in the implementations directory
+	serviceDir := filepath.Join(r.plan.ImplementationsDir, obj.Name)
+	if err := saveAndReport(serviceDir, files); err != nil {
+		result.Duration = time.Since(start)
+		return result, err
+	}
 
-	// Start spinner
+	// Compile and vet the generated code, feeding failures back to the model
+	if r.repair != nil {
+		if err := r.checkAndRepair(obj, serviceDir, messages, response, files, allowed, &result); err != nil {
+			result.Duration = time.Since(start)
+			return result, err
+		}
+	}
+
+	if r.cache != nil {
+		written := make([]string, 0, len(files))
+		for filename := range files {
+			written = append(written, filename)
+		}
+		if err := r.cache.Record(obj.Name, buildKey, written); err != nil {
+			fmt.Printf("   ⚠️  Warning: failed to record build: %v\n", err)
+		}
+	}
+
+	result.Duration = time.Since(start)
+	fmt.Printf("   ⏱️  Completed in %s\n", result.Duration)
+	fmt.Printf("   ✅ %s implemented successfully\n", obj.Name)
+
+	result.Success = true
+	return result, nil
+}
+
+// generate sends a conversation to the LLM provider and returns the files of
+// its response (write_file calls, or files extracted from the text for
+// providers without tool support) and its summary as an assistant turn.
+// write_file calls outside allowed are rejected back to the model. Usage is
+// added to result; the response is saved to the run directory with the given
+// file name suffix.
+func (r *Runner) generate(obj types.ObjectInWave, messages []Message, allowed []string, suffix string, result *ExecutionResult) (string, map[string]string, error) {
+	fmt.Printf("   🤖 Calling %s (this may take a while)...\n", r.client.Model())
+
+	// Start spinner, showing the output tokens streamed so far
+	var generated atomic.Int64
 	stopSpinner := make(chan bool)
 	go func() {
 		spinChars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
@@ -247,34 +417,72 @@ func (r *Runner) executeObject(obj types.ObjectInWave, completedObjects *int, to
 				fmt.Printf("\r   ")
 				return
 			default:
-				fmt.Printf("\r   %s Processing...", spinChars[i%len(spinChars)])
+				if tokens := generated.Load(); tokens > 0 {
+					fmt.Printf("\r   %s Generating... %d tokens", spinChars[i%len(spinChars)], tokens)
+				} else {
+					fmt.Printf("\r   %s Processing...", spinChars[i%len(spinChars)])
+				}
 				i++
 				time.Sleep(100 * time.Millisecond)
 			}
 		}
 	}()
 
-	response, err := r.client.Implement(prompt)
+	resp, usage, err := r.client.Implement(messages, allowed, func(p Progress) {
+		if p.Notice != "" {
+			fmt.Printf("\n   %s\n", p.Notice) // Retries and continuations
+			return
+		}
+		generated.Store(int64(p.OutputTokens))
+	})
 	stopSpinner <- true
 	time.Sleep(100 * time.Millisecond) // Wait for spinner cleanup
-	fmt.Println() // New line after spinner
-
+	fmt.Println()                      // New line after spinner
+
+	responseFile := filepath.Join(r.tempDir, fmt.Sprintf("tsubo-response-%s%s.md", obj.Name, suffix))
+	result.Attempts += usage.Attempts
+	result.Continuations += usage.Continuations
+	result.InputTokens += usage.InputTokens
+	result.OutputTokens += usage.OutputTokens
+	response := resp.Transcript()
 	if err != nil {
-		result.Duration = time.Since(start)
-		return result, fmt.Errorf("API call failed: %w", err)
+		if response != "" {
+			// Keep the partial response for debugging; nothing is written to the service
+			if writeErr := os.WriteFile(responseFile, []byte(response), 0644); writeErr == nil {
+				fmt.Printf("   💡 Partial response saved to: %s\n", responseFile)
+			}
+		}
+		return "", nil, fmt.Errorf("API call failed: %w", err)
+	}
+	if usage.Continuations > 0 {
+		fmt.Printf("   ↪ Response stitched from %d turns (output token limit reached %d time(s))\n", usage.Continuations+1, usage.Continuations)
 	}
 
 	result.Response = response
-	result.Duration = time.Since(start)
 
 	// Save response to file
-	responseFile := filepath.Join(r.tempDir, fmt.Sprintf("tsubo-response-%s.md", obj.Name))
 	if err := os.WriteFile(responseFile, []byte(response), 0644); err != nil {
 		fmt.Printf("[%s] Warning: failed to save response file: %v\n", obj.Name, err)
 	}
 
-	// Extract files from response
-	files := extractFiles(response)
+	files := resp.Files
+	if r.client.WritesFiles() && len(files) == 0 {
+		fmt.Printf("   ❌ ERROR: The model did not call %s\n", WriteFileTool)
+		fmt.Printf("   💡 Response saved to: %s\n", responseFile)
+		return "", nil, fmt.Errorf("no files written by the model")
+	}
+
+	if !r.client.WritesFiles() {
+		// A file left open at the end of the response would be saved half-written
+		if name, ok := unterminatedFile(resp.Text); ok {
+			fmt.Printf("   ❌ ERROR: Response ends inside an unterminated file (%s); nothing was written\n", name)
+			fmt.Printf("   💡 Response saved to: %s\n", responseFile)
+			return "", nil, fmt.Errorf("response truncated inside %s", name)
+		}
+
+		// Fallback for providers without tool support: extract files from the text
+		files = extractFiles(resp.Text)
+	}
 	if len(files) == 0 {
 		fmt.Printf("   ❌ ERROR: No files extracted from response\n")
 		fmt.Printf("   💡 Response preview (first 1000 chars):\n")
@@ -287,13 +495,12 @@ func (r *Runner) executeObject(obj types.ObjectInWave, completedObjects *int, to
 		for _, line := range lines {
 			fmt.Printf("      %s\n", line)
 		}
-		fmt.Printf("\n   💡 Tip: Check if Claude's response includes code blocks with file paths.\n")
+		fmt.Printf("\n   💡 Tip: Check if the response includes code blocks with file paths.\n")
 		fmt.Printf("   💡 Expected formats:\n")
 		fmt.Printf("      - <file path=\"main.go\">```go...```</file>\n")
 		fmt.Printf("      - `main.go`: ```go...```\n")
 		fmt.Printf("      - ```go:main.go...```\n")
-		result.Duration = time.Since(start)
-		return result, fmt.Errorf("no files extracted from Claude's response")
+		return "", nil, fmt.Errorf("no files extracted from the response")
 	}
 
 	fmt.Printf("   📦 Extracted %d file(s) from response:\n", len(files))
@@ -301,19 +508,77 @@ func (r *Runner) executeObject(obj types.ObjectInWave, completedObjects *int, to
 		fmt.Printf("      - %s\n", filename)
 	}
 
-	// Save implementation to implementations directory
-	serviceDir := filepath.Join(r.plan.ImplementationsDir, obj.Name)
-	if err := saveImplementation(serviceDir, files); err != nil {
-		result.Duration = time.Since(start)
-		return result, fmt.Errorf("failed to save implementation: %w", err)
-	}
+	return resp.Summary(), files, nil
+}
 
-	fmt.Printf("   💾 Saved to: %s\n", serviceDir)
-	fmt.Printf("   ⏱️  Completed in %s\n", result.Duration)
-	fmt.Printf("   ✅ %s implemented successfully\n", obj.Name)
+// checkAndRepair runs the repair policy's checks in serviceDir. While a check
+// fails and repair rounds remain, the model gets the failure and the current
+// files in a follow-up turn and its corrected files are merged into files,
+// which are saved again. Every round's check output, prompt and response are
+// kept in the run directory.
+func (r *Runner) checkAndRepair(obj types.ObjectInWave, serviceDir string, messages []Message, response string, files map[string]string, allowed []string, result *ExecutionResult) error {
+	prompt := messages[0]
+	for round := 0; ; round++ {
+		fmt.Printf("   🔍 Checking: %s\n", r.repair.CheckList())
+		failure, skipped := r.repair.Check(serviceDir)
+		if skipped != "" {
+			fmt.Printf("   ⚠️  Checks skipped: %s\n", skipped)
+			return nil
+		}
 
-	result.Success = true
-	return result, nil
+		logFile := filepath.Join(r.tempDir, fmt.Sprintf("tsubo-checks-%s-%d.log", obj.Name, round))
+		log := "all checks passed\n"
+		if failure != nil {
+			log = fmt.Sprintf("$ %s\n%s\n", failure.Command, failure.Output)
+		}
+		if err := os.WriteFile(logFile, []byte(log), 0644); err != nil {
+			fmt.Printf("   ⚠️  Warning: failed to save check output: %v\n", err)
+		}
+
+		if failure == nil {
+			fmt.Printf("   ✔️  Checks passed\n")
+			return nil
+		}
+
+		fmt.Printf("   ❌ %s failed:\n", failure.Command)
+		lines := strings.Split(failure.Output, "\n")
+		for i, line := range lines {
+			if i == 10 {
+				fmt.Printf("      ... (%d more lines in %s)\n", len(lines)-i, logFile)
+				break
+			}
+			fmt.Printf("      %s\n", line)
+		}
+
+		if round == r.repair.Rounds {
+			return fmt.Errorf("%s still failing after %d repair round(s); see %s", failure.Command, round, logFile)
+		}
+
+		repair := repairPrompt(failure, files, round+1, r.repair.Rounds, r.client.WritesFiles())
+		promptFile := filepath.Join(r.tempDir, fmt.Sprintf("tsubo-prompt-%s-repair-%d.md", obj.Name, round+1))
+		if err := os.WriteFile(promptFile, []byte(repair), 0644); err != nil {
+			fmt.Printf("   ⚠️  Warning: failed to save prompt file: %v\n", err)
+		}
+
+		fmt.Printf("   🔧 Repair round %d/%d\n", round+1, r.repair.Rounds)
+		conversation := []Message{prompt, {Role: "assistant", Content: response}, {Role: "user", Content: repair}}
+		fixedResponse, fixed, err := r.generate(obj, conversation, allowed, fmt.Sprintf("-repair-%d", round+1), result)
+		if err != nil {
+			return fmt.Errorf("repair round %d failed: %w", round+1, err)
+		}
+		for filename, content := range acceptFiles(fixed, allowed) {
+			// A repaired file replaces earlier files that need its path as a directory or vice versa
+			for other := pathConflict(filename, files); other != ""; other = pathConflict(filename, files) {
+				delete(files, other)
+			}
+			files[filename] = content
+		}
+		if err := saveAndReport(serviceDir, files); err != nil {
+			return err
+		}
+		response = fixedResponse
+		result.RepairRounds = round + 1
+	}
 }
 
 // extractFiles extracts files from Claude's response
@@ -380,31 +645,70 @@ func extractFiles(response string) map[string]string {
 	return files
 }
 
-// saveImplementation saves extracted files to the implementations directory
-func saveImplementation(serviceDir string, files map[string]string) error {
-	// Create service directory
-	if err := os.MkdirAll(serviceDir, 0755); err != nil {
-		return fmt.Errorf("failed to create service directory: %w", err)
-	}
-
-	// Save each file
-	for filename, content := range files {
-		filePath := filepath.Join(serviceDir, filename)
+var (
+	openTagPattern    = regexp.MustCompile(`<(create_file|file|source_file)[\s>]`)
+	tagPathPattern    = regexp.MustCompile(`^<(?:create_file>\s*<path>([^<]+)</path>|(?:file|source_file)\s+path="([^"]+)")`)
+	fenceNamePattern  = regexp.MustCompile("^```[a-z]*:(\\S+)")
+	fenceLabelPattern = regexp.MustCompile("`([^`]+)`:\\s*$")
+)
 
-		// Create subdirectories if needed
-		dir := filepath.Dir(filePath)
-		if dir != serviceDir {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", dir, err)
+// unterminatedFile reports the file that is still open at the end of a
+// response: a file tag without its closing tag, or an unclosed code fence
+func unterminatedFile(response string) (string, bool) {
+	if loc := openTagPattern.FindAllStringSubmatchIndex(response, -1); len(loc) > 0 {
+		last := loc[len(loc)-1]
+		tag := response[last[2]:last[3]]
+		if !strings.Contains(response[last[0]:], "</"+tag+">") {
+			name := "last file"
+			if m := tagPathPattern.FindStringSubmatch(response[last[0]:]); m != nil {
+				name = strings.TrimSpace(m[1] + m[2])
 			}
+			return name, true
 		}
+	}
 
-		// Write file
-		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filename, err)
-		}
+	if strings.Count(response, "```")%2 == 0 {
+		return "", false
+	}
+	last := strings.LastIndex(response, "```")
+	if m := fenceNamePattern.FindStringSubmatch(response[last:]); m != nil {
+		return m[1], true
+	}
+	if m := fenceLabelPattern.FindStringSubmatch(response[:last]); m != nil {
+		return m[1], true
 	}
+	return "last code block", true
+}
+
+// acceptFiles returns the generated files that may be written, warning about
+// the rejected ones
+func acceptFiles(files map[string]string, allowed []string) map[string]string {
+	accepted, rejected := prepareFiles(files, allowed)
+	for _, file := range rejected {
+		fmt.Printf("   ⚠️  Rejected %s: %s\n", file.Path, file.Reason)
+	}
+	return accepted
+}
 
+// saveAndReport saves files as the implementation in serviceDir and lists
+// what changed
+func saveAndReport(serviceDir string, files map[string]string) error {
+	report, err := saveImplementation(serviceDir, files)
+	if err != nil {
+		return fmt.Errorf("failed to save implementation: %w", err)
+	}
+
+	fmt.Printf("   💾 Saved to: %s (%d added, %d changed, %d removed, %d unchanged)\n",
+		serviceDir, len(report.Added), len(report.Changed), len(report.Removed), report.Unchanged)
+	for _, name := range report.Added {
+		fmt.Printf("      + %s\n", name)
+	}
+	for _, name := range report.Changed {
+		fmt.Printf("      ~ %s\n", name)
+	}
+	for _, name := range report.Removed {
+		fmt.Printf("      - %s\n", name)
+	}
 	return nil
 }
 
@@ -414,30 +718,53 @@ func PrintSummary(results []ExecutionResult) {
 	fmt.Printf("Total objects: %d\n", len(results))
 
 	successful := 0
+	skipped := 0
 	failed := 0
 	totalDuration := time.Duration(0)
+	inputTokens, outputTokens := 0, 0
 
 	for _, result := range results {
-		if result.Success {
+		if result.Skipped {
+			skipped++
+		} else if result.Success {
 			successful++
 		} else {
 			failed++
 		}
 		totalDuration += result.Duration
+		inputTokens += result.InputTokens
+		outputTokens += result.OutputTokens
 	}
 
 	fmt.Printf("Successful: %d\n", successful)
+	if skipped > 0 {
+		fmt.Printf("Up to date (skipped): %d\n", skipped)
+	}
 	fmt.Printf("Failed: %d\n", failed)
 	fmt.Printf("Total duration: %s\n", totalDuration)
+	fmt.Printf("Tokens: %d input, %d output\n", inputTokens, outputTokens)
 	fmt.Println()
 
 	fmt.Println("Details:")
 	for _, result := range results {
 		status := "✓"
-		if !result.Success {
+		if result.Skipped {
+			status = "="
+		} else if !result.Success {
 			status = "✗"
 		}
-		fmt.Printf("  %s %s (%s)\n", status, result.ObjectName, result.Duration)
+		attempts := ""
+		if result.Continuations > 0 {
+			attempts += fmt.Sprintf(", %d continuation(s)", result.Continuations)
+		}
+		// One call per turn; anything beyond that was a retry
+		if result.Attempts > 1+result.Continuations+result.RepairRounds {
+			attempts += fmt.Sprintf(", %d attempts", result.Attempts)
+		}
+		if result.RepairRounds > 0 {
+			attempts += fmt.Sprintf(", %d repair round(s)", result.RepairRounds)
+		}
+		fmt.Printf("  %s %s (%s%s)\n", status, result.ObjectName, result.Duration, attempts)
 		if !result.Success {
 			fmt.Printf("    Error: %v\n", result.Error)
 		}
@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// Schedule selects how the runner orders object implementations
+type Schedule string
+
+const (
+	// ScheduleDAG starts each object as soon as all of its dependencies have succeeded
+	ScheduleDAG Schedule = "dag"
+	// ScheduleWaves runs waves as barriers: a wave starts after every object of the previous wave
+	ScheduleWaves Schedule = "waves"
+)
+
+// ParseSchedule validates a schedule name (empty selects the default)
+func ParseSchedule(name string) (Schedule, error) {
+	switch Schedule(name) {
+	case "":
+		return ScheduleDAG, nil
+	case ScheduleDAG, ScheduleWaves:
+		return Schedule(name), nil
+	}
+	return "", fmt.Errorf("unknown schedule %q (expected dag or waves)", name)
+}
+
+// dagResult is sent by a worker when an object finishes
+type dagResult struct {
+	index  int
+	result ExecutionResult
+	err    error
+}
+
+// executeDAG dispatches every object the moment its dependencies have succeeded.
+// Waves are only used for display. Dependencies outside the plan (e.g. a
+// single-service rebuild) are treated as already satisfied.
+func (r *Runner) executeDAG() ([]ExecutionResult, error) {
+	var objects []types.ObjectInWave
+	for _, wave := range r.plan.Waves {
+		objects = append(objects, wave.Objects...)
+	}
+	totalObjects := len(objects)
+
+	fmt.Printf("\n📋 Total: %d object(s), dependency-driven scheduling\n", totalObjects)
+	for _, wave := range r.plan.Waves {
+		names := make([]string, len(wave.Objects))
+		for i, obj := range wave.Objects {
+			names[i] = obj.Name
+		}
+		fmt.Printf("   Wave %d: %s\n", wave.Wave, strings.Join(names, ", "))
+	}
+
+	indexOf := make(map[string]int, totalObjects)
+	for i, obj := range objects {
+		indexOf[obj.Name] = i
+	}
+
+	// Count unfinished dependencies and record who is waiting on each object
+	remaining := make([]int, totalObjects)
+	dependents := make([][]int, totalObjects)
+	for i, obj := range objects {
+		for _, dep := range obj.Dependencies {
+			if d, inPlan := indexOf[dep]; inPlan {
+				remaining[i]++
+				dependents[d] = append(dependents[d], i)
+			}
+		}
+	}
+
+	var ready []int
+	for i := range objects {
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	start := time.Now()
+	var mu sync.Mutex
+	completedObjects := 0
+	done := make(chan dagResult)
+	running := 0
+
+	var allResults []ExecutionResult
+	var failures []string
+	finished := 0
+
+	for len(ready) > 0 || running > 0 {
+		// Dispatch ready objects up to the concurrency limit (stop after a failure)
+		for len(ready) > 0 && len(failures) == 0 && (r.concurrency <= 0 || running < r.concurrency) {
+			index := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func(index int) {
+				result, err := r.executeObject(objects[index], &completedObjects, totalObjects, &mu)
+				done <- dagResult{index: index, result: result, err: err}
+			}(index)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-done
+		running--
+		finished++
+
+		if res.err != nil {
+			res.result.Success = false
+			res.result.Error = res.err
+		}
+		allResults = append(allResults, res.result)
+
+		if !res.result.Success {
+			failures = append(failures, fmt.Sprintf("object %s failed: %v", objects[res.index].Name, res.result.Error))
+			continue
+		}
+
+		for _, d := range dependents[res.index] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				ready = insertSorted(ready, d)
+			}
+		}
+	}
+
+	fmt.Printf("\n⏱️  Wall-clock time: %s\n", time.Since(start).Round(time.Millisecond))
+
+	if len(failures) > 0 {
+		if skipped := totalObjects - finished; skipped > 0 {
+			failures = append(failures, fmt.Sprintf("%d object(s) not started", skipped))
+		}
+		return allResults, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+
+	return allResults, nil
+}
+
+// insertSorted keeps the ready queue in plan order so dispatch is deterministic
+func insertSorted(queue []int, index int) []int {
+	i := 0
+	for i < len(queue) && queue[i] < index {
+		i++
+	}
+	queue = append(queue, 0)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = index
+	return queue
+}
@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxContinuations limits the extra turns requested when a response stops at
+// the output token limit
+const MaxContinuations = 4
+
+// ErrTruncated is returned when a response still hits the output token limit
+// after MaxContinuations continuation turns
+var ErrTruncated = errors.New("response truncated at the output token limit")
+
+// Progress is a progress update of a call
+type Progress struct {
+	OutputTokens int    // Output tokens generated so far (across all continuation turns)
+	Notice       string // Status message such as a retry or a continuation ("" for token updates)
+}
+
+// ProgressFunc receives the progress of a call. It may be nil.
+type ProgressFunc func(Progress)
+
+// tokens reports the output tokens generated so far
+func (f ProgressFunc) tokens(outputTokens int) {
+	if f != nil {
+		f(Progress{OutputTokens: outputTokens})
+	}
+}
+
+// notice reports a status message
+func (f ProgressFunc) notice(format string, args ...interface{}) {
+	if f != nil {
+		f(Progress{Notice: fmt.Sprintf(format, args...)})
+	}
+}
+
+// after returns a ProgressFunc for a later turn of a call, which adds the
+// output tokens of the earlier turns
+func (f ProgressFunc) after(outputTokens int) ProgressFunc {
+	if f == nil {
+		return nil
+	}
+	return func(p Progress) {
+		if p.Notice == "" {
+			p.OutputTokens += outputTokens
+		}
+		f(p)
+	}
+}
+
+// continuationPrompt asks the model to resume a text response that was cut off
+const continuationPrompt = "Your previous response was cut off by the output token limit. " +
+	"Continue exactly where it stopped, mid-line if necessary. " +
+	"Do not repeat anything, do not add a preamble and do not restart the current file."
+
+// turnResult is one streamed turn of a call
+type turnResult struct {
+	text      string // the response so far, stitched onto the earlier turns
+	truncated bool   // stopped at the output token limit
+	usage     Usage
+}
+
+// readEvents reads a server-sent event stream and passes the payload of each
+// data line to handle, stopping at the end of the stream or at "[DONE]"
+func readEvents(body io.Reader, handle func(data []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue // event names, comments and blank separators
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if string(data) == "[DONE]" {
+			return nil
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := handle(data); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &APIError{Kind: ErrNetwork, Message: fmt.Sprintf("failed to read response stream: %v", err), Err: err}
+	}
+	return nil
+}
+
+// streamErrorKind maps the error type of an in-stream error event to an error kind
+func streamErrorKind(errorType string) ErrorKind {
+	switch errorType {
+	case "rate_limit_error":
+		return ErrRateLimited
+	case "overloaded_error":
+		return ErrOverloaded
+	case "invalid_request_error", "authentication_error", "permission_error", "not_found_error":
+		return ErrClient
+	}
+	return ErrServer
+}
+
+// continueResponse runs turns until a response is complete. turn receives the
+// response so far (empty for the first turn) and the output tokens generated
+// by the earlier turns. Continuations are announced to progress.
+func continueResponse(progress ProgressFunc, turn func(partial string, outputTokens int) (turnResult, error)) (string, Usage, error) {
+	text := ""
+	var usage Usage
+	for continuation := 0; ; continuation++ {
+		result, err := turn(text, usage.OutputTokens)
+		if result.text != "" {
+			text = result.text
+		}
+		usage.InputTokens += result.usage.InputTokens
+		usage.OutputTokens += result.usage.OutputTokens
+		usage.Attempts += result.usage.Attempts
+		if err != nil {
+			return text, usage, err
+		}
+		if !result.truncated {
+			return text, usage, nil
+		}
+		if continuation == MaxContinuations {
+			return text, usage, fmt.Errorf("%w after %d continuations (%d output tokens)", ErrTruncated, continuation, usage.OutputTokens)
+		}
+		usage.Continuations++
+		progress.notice("↪ Output token limit reached, requesting continuation %d/%d", usage.Continuations, MaxContinuations)
+	}
+}
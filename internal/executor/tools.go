@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// WriteFileTool is the tool the model writes each generated file with
+const WriteFileTool = "write_file"
+
+// MaxToolTurns limits the tool-use round trips of one call
+const MaxToolTurns = 32
+
+// writeFileSchema is the input schema of the write_file tool
+var writeFileSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "path": {"type": "string", "description": "File path relative to the service directory, e.g. main.go or handlers/user.go"},
+    "content": {"type": "string", "description": "Complete file content"}
+  },
+  "required": ["path", "content"]
+}`)
+
+// writeFileInput is the input of a write_file call
+type writeFileInput struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// partialPathPattern finds the path in the input of a write_file call that was cut off
+var partialPathPattern = regexp.MustCompile(`"path"\s*:\s*"([^"]*)"`)
+
+// Response is the outcome of an implementation call
+type Response struct {
+	Text  string            // Text output of the model
+	Files map[string]string // Files written with the write_file tool (nil for providers without tool support)
+}
+
+// Transcript renders the response with the files it wrote, for the run's artifacts
+func (r Response) Transcript() string {
+	if len(r.Files) == 0 {
+		return r.Text
+	}
+	var transcript strings.Builder
+	transcript.WriteString(r.Text)
+	if r.Text != "" {
+		transcript.WriteString("\n\n")
+	}
+	transcript.WriteString(formatFiles(r.Files))
+	return transcript.String()
+}
+
+// Summary is the response as an assistant turn of a follow-up conversation.
+// Written files are only named: follow-up prompts carry the current files.
+func (r Response) Summary() string {
+	if len(r.Files) == 0 {
+		return r.Text
+	}
+	summary := fmt.Sprintf("(Wrote %d file(s) with %s: %s)", len(r.Files), WriteFileTool, strings.Join(sortedNames(r.Files), ", "))
+	if strings.TrimSpace(r.Text) == "" {
+		return summary
+	}
+	return r.Text + "\n\n" + summary
+}
+
+// formatFiles lists files in the <create_file> format, sorted by path
+func formatFiles(files map[string]string) string {
+	var out strings.Builder
+	for _, name := range sortedNames(files) {
+		out.WriteString("<create_file>\n")
+		out.WriteString(fmt.Sprintf("<path>%s</path>\n", name))
+		out.WriteString("<content>\n")
+		out.WriteString(files[name])
+		out.WriteString("\n</content>\n")
+		out.WriteString("</create_file>\n\n")
+	}
+	return out.String()
+}
+
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeFile records a write_file call in files and returns its tool result.
+// Paths that fail checkPath are not recorded and come back as errors, so the
+// model can write the file elsewhere.
+func writeFile(call ContentBlock, files map[string]string, allowed []string) ContentBlock {
+	result := ContentBlock{Type: "tool_result", ToolUseID: call.ID}
+
+	var input writeFileInput
+	switch {
+	case call.Name != WriteFileTool:
+		result.Content = fmt.Sprintf("unknown tool %q; use %s", call.Name, WriteFileTool)
+		result.IsError = true
+	case json.Unmarshal(call.Input, &input) != nil || strings.TrimSpace(input.Path) == "":
+		result.Content = "invalid input: path and content are required"
+		result.IsError = true
+	default:
+		path, err := checkPath(input.Path, allowed)
+		if err == nil {
+			if other := pathConflict(path, files); other != "" {
+				err = fmt.Errorf("conflicts with %s, which was already written: a path cannot be both a file and a directory", other)
+			}
+		}
+		if err != nil {
+			result.Content = fmt.Sprintf("rejected %s: %v; the file was NOT saved", input.Path, err)
+			if len(allowed) > 0 {
+				result.Content += fmt.Sprintf(" (allowed files: %s)", strings.Join(allowed, ", "))
+			}
+			result.IsError = true
+			break
+		}
+		files[path] = input.Content
+		result.Content = "wrote " + path
+	}
+	return result
+}
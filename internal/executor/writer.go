@@ -0,0 +1,374 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+)
+
+// preservedFiles are kept from the previous implementation unless the model
+// writes them: CLAUDE.md is written by Potter before generation and go.sum by
+// the build checks
+var preservedFiles = []string{"CLAUDE.md", "go.sum"}
+
+const (
+	stagingInfix = ".staging-" // Staging directories are named .<service>.staging-<random>
+	backupSuffix = ".old"      // Added to the staging name for the implementation moved aside by swapDir
+)
+
+// RejectedFile is a generated file that was not written
+type RejectedFile struct {
+	Path   string
+	Reason string
+}
+
+// WriteReport describes how saving an implementation changed its directory
+type WriteReport struct {
+	Added     []string
+	Changed   []string
+	Removed   []string // Stale files of the previous implementation
+	Unchanged int
+}
+
+// cleanPath validates a model-provided file path and returns it relative to
+// the service directory, in slash form. Absolute paths and paths with ".."
+// elements are rejected, even if they would resolve inside the directory.
+func cleanPath(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("empty path")
+	case strings.ContainsRune(name, 0):
+		return "", fmt.Errorf("path contains a NUL byte")
+	case filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) || filepath.VolumeName(name) != "":
+		return "", fmt.Errorf("absolute path")
+	}
+
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	for _, elem := range strings.Split(slashed, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("path contains \"..\"")
+		}
+	}
+
+	cleaned := path.Clean(slashed)
+	if cleaned == "." {
+		return "", fmt.Errorf("not a file path")
+	}
+	return cleaned, nil
+}
+
+// matchGlob matches a slash-separated path against a glob pattern. Segments
+// use path.Match syntax; a "**" segment matches any number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// prepareFiles normalizes the paths of generated files and drops the files
+// that must not be written: unsafe paths, paths outside the allowlist (if
+// any) and files whose path another file uses as a directory
+func prepareFiles(files map[string]string, allowed []string) (map[string]string, []RejectedFile) {
+	accepted := make(map[string]string, len(files))
+	var rejected []RejectedFile
+	for _, name := range sortedNames(files) {
+		cleaned, err := checkPath(name, allowed)
+		if err != nil {
+			rejected = append(rejected, RejectedFile{Path: name, Reason: err.Error()})
+			continue
+		}
+		accepted[cleaned] = files[name]
+	}
+
+	// Sorted, so a/b (which needs a as a directory) has not been dropped when a is checked
+	for _, name := range sortedNames(accepted) {
+		if other := pathConflict(name, accepted); other != "" {
+			rejected = append(rejected, RejectedFile{Path: name, Reason: fmt.Sprintf("%s uses it as a directory", other)})
+			delete(accepted, name)
+		}
+	}
+	return accepted, rejected
+}
+
+// pathConflict returns a file of files that cannot be written together with
+// name because one of them needs the other as its directory ("" if none)
+func pathConflict(name string, files map[string]string) string {
+	for other := range files {
+		if strings.HasPrefix(other, name+"/") || strings.HasPrefix(name, other+"/") {
+			return other
+		}
+	}
+	return ""
+}
+
+// checkPath validates a generated file path with cleanPath and the allowlist
+// (if any) and returns it cleaned
+func checkPath(name string, allowed []string) (string, error) {
+	cleaned, err := cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+	if len(allowed) > 0 && !allowedFile(cleaned, allowed) {
+		return "", fmt.Errorf("not in the architecture's allowed_files")
+	}
+	return cleaned, nil
+}
+
+func allowedFile(name string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedFiles returns the file globs allowed by the architecture of a
+// contract (nil if it has no architecture or no allowlist, or for the
+// generated gateway, which has no contract)
+func allowedFiles(contractPath string) ([]string, error) {
+	if contractPath == "" {
+		return nil, nil
+	}
+	objectDef, err := parser.ParseObjectFile(contractPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contract %s: %w", contractPath, err)
+	}
+	if objectDef.Service.Architecture == "" {
+		return nil, nil
+	}
+
+	archPath := filepath.Join(filepath.Dir(contractPath), objectDef.Service.Architecture)
+	archDef, err := parser.ParseArchitectureFile(archPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load architecture %s: %w", archPath, err)
+	}
+	return archDef.Architecture.AllowedFiles, nil
+}
+
+// saveImplementation replaces serviceDir with files. The new implementation
+// is written to a staging directory next to it and swapped in with two
+// renames (see swapDir), so a failed write leaves the old implementation
+// untouched. Files of the previous implementation that are not written again
+// are removed, except preservedFiles. File paths must have been checked by
+// prepareFiles.
+func saveImplementation(serviceDir string, files map[string]string) (*WriteReport, error) {
+	if info, err := os.Lstat(serviceDir); err == nil {
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return nil, fmt.Errorf("refusing to replace %s: it is a symlink", serviceDir)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("refusing to replace %s: not a directory", serviceDir)
+		}
+	}
+
+	parent := filepath.Dir(serviceDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(serviceDir)+stagingInfix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging) // No-op once swapped in
+	if err := os.Chmod(staging, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	previous, err := readImplementation(serviceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make(map[string][]byte, len(files)+len(preservedFiles))
+	for name, content := range files {
+		next[name] = []byte(content)
+	}
+	for _, name := range preservedFiles {
+		if _, written := next[name]; !written && pathConflict(name, files) == "" {
+			if content, ok := previous[name]; ok {
+				next[name] = content
+			}
+		}
+	}
+
+	for name, content := range next {
+		filePath := filepath.Join(staging, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(filePath, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write file %s: %w", name, err)
+		}
+	}
+
+	if err := swapDir(staging, serviceDir); err != nil {
+		return nil, err
+	}
+
+	report := &WriteReport{}
+	for name, content := range next {
+		old, existed := previous[name]
+		switch {
+		case !existed:
+			report.Added = append(report.Added, name)
+		case !bytes.Equal(old, content):
+			report.Changed = append(report.Changed, name)
+		default:
+			report.Unchanged++
+		}
+	}
+	for name := range previous {
+		if _, kept := next[name]; !kept {
+			report.Removed = append(report.Removed, name)
+		}
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Changed)
+	sort.Strings(report.Removed)
+	return report, nil
+}
+
+// readImplementation reads the regular files of an existing service directory,
+// keyed by slash-separated relative path. Symlinks are never followed; they
+// are listed with no content so that the swap reports them as removed.
+func readImplementation(serviceDir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(serviceDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == serviceDir && os.IsNotExist(err) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(serviceDir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !d.Type().IsRegular() {
+			files[name] = nil
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous implementation: %w", err)
+	}
+	return files, nil
+}
+
+// swapDir moves staging to dir, replacing the current dir. The old directory
+// is renamed aside to a backup first and restored if the swap fails. The two
+// renames are not atomic together: if the process dies between them, dir is
+// missing until recoverStaging restores the backup.
+func swapDir(staging, dir string) error {
+	backup := ""
+	if _, err := os.Lstat(dir); err == nil {
+		backup = staging + backupSuffix
+		if err := os.Rename(dir, backup); err != nil {
+			return fmt.Errorf("failed to move previous implementation aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(staging, dir); err != nil {
+		if backup != "" {
+			if restoreErr := os.Rename(backup, dir); restoreErr != nil {
+				return fmt.Errorf("failed to replace implementation: %w (and failed to restore the previous implementation from %s: %v)", err, backup, restoreErr)
+			}
+		}
+		return fmt.Errorf("failed to replace implementation: %w", err)
+	}
+
+	if backup != "" {
+		if err := os.RemoveAll(backup); err != nil {
+			return fmt.Errorf("failed to remove previous implementation: %w", err)
+		}
+	}
+	return nil
+}
+
+// recoverStaging cleans up after saves that were interrupted in
+// implementationsDir. Staging directories are removed. A backup whose service
+// directory is missing (the process died between the renames of swapDir) is
+// renamed back, so the previous implementation is restored; other backups are
+// removed. It returns a description of each action taken.
+func recoverStaging(implementationsDir string) ([]string, error) {
+	entries, err := os.ReadDir(implementationsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", implementationsDir, err)
+	}
+
+	var actions []string
+	for _, entry := range entries {
+		service, ok := stagingService(entry.Name())
+		if !ok {
+			continue
+		}
+		leftover := filepath.Join(implementationsDir, entry.Name())
+		serviceDir := filepath.Join(implementationsDir, service)
+
+		if strings.HasSuffix(entry.Name(), backupSuffix) {
+			if _, err := os.Lstat(serviceDir); os.IsNotExist(err) {
+				if err := os.Rename(leftover, serviceDir); err != nil {
+					return actions, fmt.Errorf("failed to restore %s from %s: %w", serviceDir, leftover, err)
+				}
+				actions = append(actions, fmt.Sprintf("restored %s from an interrupted save", service))
+				continue
+			}
+		}
+		if err := os.RemoveAll(leftover); err != nil {
+			return actions, fmt.Errorf("failed to remove %s: %w", leftover, err)
+		}
+		actions = append(actions, fmt.Sprintf("removed %s", entry.Name()))
+	}
+	return actions, nil
+}
+
+// stagingService returns the service of a staging or backup directory name
+// (".<service>.staging-<random>[.old]")
+func stagingService(name string) (string, bool) {
+	i := strings.LastIndex(name, stagingInfix)
+	if !strings.HasPrefix(name, ".") || i <= 1 {
+		return "", false
+	}
+	return name[1:i], true
+}
@@ -0,0 +1,292 @@
+package executor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "main.go", want: "main.go"},
+		{name: "handlers/user.go", want: "handlers/user.go"},
+		{name: "./pkg//util.go", want: "pkg/util.go"},
+		{name: "  main.go\n", want: "main.go"},
+		{name: `handlers\user.go`, want: "handlers/user.go"},
+		{name: "dir/", want: "dir"},
+		{name: "", wantErr: "empty path"},
+		{name: "   ", wantErr: "empty path"},
+		{name: ".", wantErr: "not a file path"},
+		{name: "./", wantErr: "not a file path"},
+		{name: "/etc/passwd", wantErr: "absolute path"},
+		{name: `\windows\system.ini`, wantErr: "absolute path"},
+		{name: "//server/share", wantErr: "absolute path"},
+		{name: "..", wantErr: `".."`},
+		{name: "../evil.go", wantErr: `".."`},
+		{name: "a/../../evil.go", wantErr: `".."`},
+		{name: "a/../b.go", wantErr: `".."`}, // Rejected even though it stays inside
+		{name: `a\..\..\evil.go`, wantErr: `".."`},
+		{name: "main.go\x00.txt", wantErr: "NUL byte"},
+		{name: "..foo/bar.go", want: "..foo/bar.go"}, // Not a ".." element
+	}
+
+	for _, tt := range tests {
+		got, err := cleanPath(tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("cleanPath(%q) = %q, %v; want error containing %q", tt.name, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("cleanPath(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "handlers/user.go", false}, // * does not cross directories
+		{"go.mod", "go.mod", true},
+		{"go.mod", "sub/go.mod", false},
+		{"handlers/*.go", "handlers/user.go", true},
+		{"handlers/*.go", "handlers/v1/user.go", false},
+		{"**", "anything/at/all.txt", true},
+		{"**/*.go", "main.go", true}, // ** matches zero directories
+		{"**/*.go", "a/b/c.go", true},
+		{"**/*.go", "a/b/c.txt", false},
+		{"domain/**", "domain/user.go", true},
+		{"domain/**", "domain/model/user.go", true},
+		{"domain/**", "domain", true},
+		{"domain/**", "usecase/user.go", false},
+		{"a/**/b.go", "a/b.go", true},
+		{"a/**/b.go", "a/x/y/b.go", true},
+		{"a/**/b.go", "b.go", false},
+		{"[", "[", false}, // Malformed pattern never matches
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareFiles(t *testing.T) {
+	files := map[string]string{
+		"./main.go":      "main",
+		"../evil.go":     "evil",
+		"/tmp/abs.go":    "abs",
+		"README.md":      "readme",
+		"domain/user.go": "user",
+		"domain":         "blocks the domain directory",
+	}
+
+	accepted, rejected := prepareFiles(files, []string{"*.go", "domain/**"})
+
+	wantAccepted := map[string]string{"main.go": "main", "domain/user.go": "user"}
+	if !reflect.DeepEqual(accepted, wantAccepted) {
+		t.Errorf("accepted = %v, want %v", accepted, wantAccepted)
+	}
+
+	reasons := make(map[string]string)
+	for _, r := range rejected {
+		reasons[r.Path] = r.Reason
+	}
+	for path, want := range map[string]string{
+		"../evil.go":  `".."`,
+		"/tmp/abs.go": "absolute path",
+		"README.md":   "allowed_files",
+		"domain":      "domain/user.go uses it as a directory",
+	} {
+		if !strings.Contains(reasons[path], want) {
+			t.Errorf("rejection of %s = %q, want it to contain %q", path, reasons[path], want)
+		}
+	}
+	if len(rejected) != 4 {
+		t.Errorf("rejected %d files, want 4: %v", len(rejected), rejected)
+	}
+}
+
+func TestPrepareFilesWithoutAllowlist(t *testing.T) {
+	accepted, rejected := prepareFiles(map[string]string{"README.md": "r", "a/b/c.txt": "c"}, nil)
+	if len(accepted) != 2 || len(rejected) != 0 {
+		t.Errorf("accepted %v, rejected %v; want both files accepted", accepted, rejected)
+	}
+}
+
+func TestWriteFileRejectsPaths(t *testing.T) {
+	call := func(id, path string) ContentBlock {
+		input, _ := json.Marshal(writeFileInput{Path: path, Content: id})
+		return ContentBlock{Type: "tool_use", ID: id, Name: WriteFileTool, Input: input}
+	}
+	files := make(map[string]string)
+	allowed := []string{"**/*.go", "a"}
+
+	if result := writeFile(call("1", "./pkg/util.go"), files, allowed); result.IsError {
+		t.Fatalf("valid path rejected: %s", result.Content)
+	}
+	for _, path := range []string{"../escape.go", "/tmp/abs.go", "README.md", "pkg"} {
+		result := writeFile(call("2", path), files, allowed)
+		if !result.IsError || !strings.Contains(result.Content, "NOT saved") {
+			t.Errorf("write_file(%q) = %+v, want an error result", path, result)
+		}
+	}
+
+	want := map[string]string{"pkg/util.go": "1"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+func TestSaveImplementation(t *testing.T) {
+	serviceDir := filepath.Join(t.TempDir(), "implementations", "svc")
+
+	report, err := saveImplementation(serviceDir, map[string]string{"main.go": "v1", "old.go": "old", "sub/a.go": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.go", "old.go", "sub/a.go"}; !reflect.DeepEqual(report.Added, want) {
+		t.Errorf("first save added %v, want %v", report.Added, want)
+	}
+
+	// Written by Potter and the checks, not the model
+	writeTestFile(t, filepath.Join(serviceDir, "CLAUDE.md"), "architecture")
+	writeTestFile(t, filepath.Join(serviceDir, "notes.txt"), "hand-written")
+	if err := os.Symlink("/etc/passwd", filepath.Join(serviceDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = saveImplementation(serviceDir, map[string]string{"main.go": "v2", "sub/a.go": "a", "new.go": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &WriteReport{
+		Added:     []string{"new.go"},
+		Changed:   []string{"main.go"},
+		Removed:   []string{"link", "notes.txt", "old.go"},
+		Unchanged: 2, // sub/a.go and the preserved CLAUDE.md
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("second save report = %+v, want %+v", report, want)
+	}
+
+	got := readTestTree(t, serviceDir)
+	wantTree := map[string]string{"CLAUDE.md": "architecture", "main.go": "v2", "new.go": "new", "sub/a.go": "a"}
+	if !reflect.DeepEqual(got, wantTree) {
+		t.Errorf("service directory = %v, want %v", got, wantTree)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(serviceDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("implementations directory has %d entries, want only svc (staging directories must be removed)", len(entries))
+	}
+}
+
+func TestSaveImplementationPreservedFileConflict(t *testing.T) {
+	serviceDir := filepath.Join(t.TempDir(), "svc")
+	writeTestFile(t, filepath.Join(serviceDir, "go.sum"), "sums")
+
+	// The model uses go.sum as a directory: the old go.sum must not be carried over
+	if _, err := saveImplementation(serviceDir, map[string]string{"go.sum/x": "x"}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if got := readTestTree(t, serviceDir); !reflect.DeepEqual(got, map[string]string{"go.sum/x": "x"}) {
+		t.Errorf("service directory = %v", got)
+	}
+}
+
+func TestSaveImplementationRefusesSymlink(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "elsewhere")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "svc")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := saveImplementation(link, map[string]string{"main.go": "x"}); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Fatalf("save through a symlink = %v, want a symlink error", err)
+	}
+	if entries, _ := os.ReadDir(target); len(entries) != 0 {
+		t.Errorf("symlink target was written to: %v", entries)
+	}
+}
+
+func TestRecoverStaging(t *testing.T) {
+	dir := t.TempDir()
+
+	// Interrupted between the renames: the service directory is missing
+	writeTestFile(t, filepath.Join(dir, ".lost.staging-1", "main.go"), "new")
+	writeTestFile(t, filepath.Join(dir, ".lost.staging-1.old", "main.go"), "old")
+	// Interrupted after the swap: the backup is stale
+	writeTestFile(t, filepath.Join(dir, "done", "main.go"), "new")
+	writeTestFile(t, filepath.Join(dir, ".done.staging-2.old", "main.go"), "old")
+	// Interrupted while writing the staging directory
+	writeTestFile(t, filepath.Join(dir, "partial", "main.go"), "current")
+	writeTestFile(t, filepath.Join(dir, ".partial.staging-3", "main.go"), "half")
+	// Not Potter's
+	writeTestFile(t, filepath.Join(dir, ".hidden", "x"), "x")
+
+	actions, err := recoverStaging(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 4 {
+		t.Errorf("actions = %v, want 4", actions)
+	}
+
+	tree := readTestTree(t, dir)
+	want := map[string]string{
+		"lost/main.go":    "old",
+		"done/main.go":    "new",
+		"partial/main.go": "current",
+		".hidden/x":       "x",
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("after recovery = %v, want %v", tree, want)
+	}
+
+	if actions, err := recoverStaging(filepath.Join(dir, "missing")); err != nil || len(actions) != 0 {
+		t.Errorf("recoverStaging of a missing directory = %v, %v", actions, err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files, err := readImplementation(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := make(map[string]string, len(files))
+	for name, content := range files {
+		tree[name] = string(content)
+	}
+	return tree
+}
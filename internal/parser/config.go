@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/staka121/potter/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the project configuration file searched upward from the tsubo file
+const ConfigFileName = "potter.yaml"
+
+// Defaults used when potter.yaml is absent or leaves a setting empty
+const (
+	DefaultImplementationsDir = "implementations"
+	DefaultArtifactsDir       = "/tmp/potter"
+)
+
+// DefaultContextFiles are the docs (relative to the project root) given to AI agents
+var DefaultContextFiles = []string{
+	"docs/PHILOSOPHY.md",
+	"docs/DEVELOPMENT_PRINCIPLES.md",
+	"docs/WHY_GO.md",
+	"docs/CONTRACT_DESIGN.md",
+}
+
+// FindProjectConfig searches startDir and its parents for potter.yaml.
+// It returns an empty path if none is found.
+func FindProjectConfig(startDir string) (string, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", startDir, err)
+	}
+
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ParseProjectConfigFile parses a potter.yaml file as written (no defaults applied)
+func ParseProjectConfigFile(filePath string) (*types.ProjectConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	var config types.ProjectConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && len(bytes.TrimSpace(data)) > 0 {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if config.Concurrency < 0 {
+		return nil, fmt.Errorf("failed to parse %s: concurrency must be >= 0", filePath)
+	}
+
+	config.Path = filePath
+	return &config, nil
+}
+
+// LoadProjectConfig discovers potter.yaml upward from a tsubo file (or directory)
+// and returns it with defaults applied and all paths resolved. Relative paths in
+// potter.yaml are relative to the file itself; context files are relative to the
+// project root. Without a potter.yaml the historical layout is assumed.
+func LoadProjectConfig(path string) (*types.ProjectConfig, error) {
+	baseDir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		baseDir = filepath.Dir(path)
+	}
+
+	configFile, err := FindProjectConfig(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &types.ProjectConfig{}
+	if configFile != "" {
+		config, err = ParseProjectConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configDir := filepath.Dir(configFile)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(configDir, p)
+	}
+
+	switch {
+	case config.ProjectRoot != "":
+		config.ProjectRoot = resolve(config.ProjectRoot)
+	case configFile != "":
+		config.ProjectRoot = configDir
+	default:
+		config.ProjectRoot = GetProjectRoot(baseDir)
+	}
+
+	if config.ImplementationsDir != "" {
+		config.ImplementationsDir = resolve(config.ImplementationsDir)
+	} else {
+		config.ImplementationsDir = filepath.Join(baseDir, DefaultImplementationsDir)
+	}
+
+	if config.ArtifactsDir != "" {
+		config.ArtifactsDir = resolve(config.ArtifactsDir)
+	} else {
+		config.ArtifactsDir = DefaultArtifactsDir
+	}
+
+	if config.ContextFiles == nil {
+		config.ContextFiles = DefaultContextFiles
+	}
+	contextFiles := make([]string, len(config.ContextFiles))
+	for i, f := range config.ContextFiles {
+		if filepath.IsAbs(f) {
+			contextFiles[i] = f
+		} else {
+			contextFiles[i] = filepath.Join(config.ProjectRoot, f)
+		}
+	}
+	config.ContextFiles = contextFiles
+
+	return config, nil
+}
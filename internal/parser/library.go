@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/staka121/potter/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseTypesFile parses a .types.yaml shared type library
+func ParseTypesFile(filePath string) (*types.TypeLibrary, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read types file: %w", err)
+	}
+
+	return ParseTypesYAML(data)
+}
+
+// ParseTypesYAML parses a shared type library from raw YAML bytes
+func ParseTypesYAML(data []byte) (*types.TypeLibrary, error) {
+	var library types.TypeLibrary
+	if err := yaml.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("failed to parse types YAML: %w", err)
+	}
+
+	return &library, nil
+}
+
+// ImportPath resolves an import path relative to the contract's directory
+func ImportPath(contractPath, importPath string) string {
+	if filepath.IsAbs(importPath) {
+		return importPath
+	}
+	return filepath.Join(filepath.Dir(contractPath), importPath)
+}
+
+// ResolveImports loads every library imported by an object contract
+// and fills object.ImportedTypes
+func ResolveImports(object *types.ObjectDefinition, contractPath string) error {
+	object.ImportedTypes = make(map[string]map[string]types.TypeDef, len(object.Imports))
+	for _, imp := range object.Imports {
+		library, err := ParseTypesFile(ImportPath(contractPath, imp))
+		if err != nil {
+			return fmt.Errorf("failed to load import %s: %w", imp, err)
+		}
+		object.ImportedTypes[imp] = library.Types
+	}
+	return nil
+}
+
+// ResolveImportsFromSnapshots fills object.ImportedTypes from saved library
+// contents keyed by import path. Imports without a snapshot are left unresolved.
+func ResolveImportsFromSnapshots(object *types.ObjectDefinition, snapshots map[string]string) error {
+	object.ImportedTypes = make(map[string]map[string]types.TypeDef, len(object.Imports))
+	for _, imp := range object.Imports {
+		snapshot, ok := snapshots[imp]
+		if !ok {
+			continue
+		}
+		library, err := ParseTypesYAML([]byte(snapshot))
+		if err != nil {
+			return fmt.Errorf("failed to parse snapshot of import %s: %w", imp, err)
+		}
+		object.ImportedTypes[imp] = library.Types
+	}
+	return nil
+}
@@ -3,27 +3,70 @@ package parser
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/staka121/potter/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
-// ParseObjectFile parses a .object.yaml file
+// ParseObjectFile parses a .object.yaml file and resolves its imported type libraries
 func ParseObjectFile(filePath string) (*types.ObjectDefinition, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object file: %w", err)
 	}
 
-	return ParseObjectYAML(data)
+	object, err := ParseObjectYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ResolveImports(object, filePath); err != nil {
+		return nil, err
+	}
+
+	return object, nil
 }
 
-// ParseObjectYAML parses object definition from raw YAML bytes
+// ParseObjectYAML parses object definition from raw YAML bytes.
+// Imports are not resolved; see ResolveImports.
 func ParseObjectYAML(data []byte) (*types.ObjectDefinition, error) {
 	var object types.ObjectDefinition
 	if err := yaml.Unmarshal(data, &object); err != nil {
 		return nil, fmt.Errorf("failed to parse object YAML: %w", err)
 	}
 
+	normalizeSemantics(&object)
+
 	return &object, nil
 }
+
+// normalizeSemantics trims block scalars and parses edge case status codes
+func normalizeSemantics(object *types.ObjectDefinition) {
+	for i := range object.API.Endpoints {
+		sem := &object.API.Endpoints[i].Semantics
+		sem.Intent = strings.TrimSpace(sem.Intent)
+		sem.Behavior.Success = strings.TrimSpace(sem.Behavior.Success)
+
+		for j := range sem.Behavior.EdgeCases {
+			ec := &sem.Behavior.EdgeCases[j]
+			ec.Case = strings.TrimSpace(ec.Case)
+			ec.Reason = strings.TrimSpace(ec.Reason)
+			ec.Status = parseStatusCode(ec.Response)
+		}
+	}
+}
+
+// parseStatusCode extracts the leading status code from strings like "404 Not Found"
+func parseStatusCode(response string) int {
+	fields := strings.Fields(response)
+	if len(fields) == 0 {
+		return 0
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0
+	}
+	return code
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/staka121/potter/pkg/types"
 	"gopkg.in/yaml.v3"
@@ -16,14 +17,125 @@ func ParseTsuboFile(filePath string) (*types.TsuboDefinition, error) {
 		return nil, fmt.Errorf("failed to read tsubo file: %w", err)
 	}
 
+	return ParseTsuboYAML(data)
+}
+
+// ParseTsuboYAML parses a tsubo definition from raw YAML bytes
+func ParseTsuboYAML(data []byte) (*types.TsuboDefinition, error) {
 	var tsubo types.TsuboDefinition
 	if err := yaml.Unmarshal(data, &tsubo); err != nil {
 		return nil, fmt.Errorf("failed to parse tsubo YAML: %w", err)
 	}
 
+	applyTsuboDefaults(&tsubo)
+
 	return &tsubo, nil
 }
 
+// Default Docker network settings used when deployment.network is omitted
+const (
+	DefaultNetworkName   = "tsubo-network"
+	DefaultNetworkDriver = "bridge"
+)
+
+// Default gateway settings used when the gateway section omits them
+const (
+	DefaultGatewayName = "gateway-service"
+	DefaultGatewayPort = 8080
+)
+
+// applyTsuboDefaults fills in optional settings that were not declared
+func applyTsuboDefaults(tsubo *types.TsuboDefinition) {
+	if tsubo.Gateway.Name == "" {
+		tsubo.Gateway.Name = DefaultGatewayName
+	}
+	if tsubo.Gateway.Port == 0 {
+		tsubo.Gateway.Port = DefaultGatewayPort
+	}
+	if tsubo.Deployment.Network.Name == "" {
+		tsubo.Deployment.Network.Name = DefaultNetworkName
+	}
+	if tsubo.Deployment.Network.Driver == "" {
+		tsubo.Deployment.Network.Driver = DefaultNetworkDriver
+	}
+}
+
+// GatewayEnabled reports whether the tsubo gets a generated gateway: as declared
+// by gateway.enabled, otherwise whenever the tsubo has more than one object
+func GatewayEnabled(tsubo *types.TsuboDefinition) bool {
+	if tsubo.Gateway.Enabled != nil {
+		return *tsubo.Gateway.Enabled
+	}
+	return len(tsubo.Objects) > 1
+}
+
+// GatewayRoutes resolves how the gateway exposes each object of the tsubo.
+// Paths declared in gateway.routes win; otherwise the prefixes are derived
+// from the object's contract (base_path + first segment of each endpoint),
+// falling back to /api/v1/<plural of the service name>.
+func GatewayRoutes(tsubo *types.TsuboDefinition, contractsDir string) []types.GatewayRoute {
+	overrides := make(map[string]types.GatewayRoute)
+	for _, route := range tsubo.Gateway.Routes {
+		overrides[route.Service] = route
+	}
+
+	routes := make([]types.GatewayRoute, 0, len(tsubo.Objects))
+	for _, objRef := range tsubo.Objects {
+		route := overrides[objRef.Name]
+		route.Service = objRef.Name
+		if len(route.Paths) == 0 && !route.Exclude {
+			route.Paths = contractRoutePaths(filepath.Join(contractsDir, objRef.Contract))
+		}
+		if len(route.Paths) == 0 && !route.Exclude {
+			route.Paths = []string{InferPathPrefix(objRef.Name)}
+		}
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// contractRoutePaths derives route prefixes from a contract's endpoints
+func contractRoutePaths(contractPath string) []string {
+	objectDef, err := ParseObjectFile(contractPath)
+	if err != nil {
+		return nil
+	}
+
+	basePath := strings.TrimSuffix(objectDef.API.BasePath, "/")
+	var paths []string
+	seen := make(map[string]bool)
+	for _, ep := range objectDef.API.Endpoints {
+		segment := strings.SplitN(strings.TrimPrefix(ep.Path, "/"), "/", 2)[0]
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+		prefix := basePath + "/" + segment
+		if !seen[prefix] {
+			seen[prefix] = true
+			paths = append(paths, prefix)
+		}
+	}
+
+	return paths
+}
+
+// InferPathPrefix infers the API path prefix from a service name
+// Examples:
+//
+//	user-service -> /api/v1/users
+//	todo-service -> /api/v1/todos
+func InferPathPrefix(serviceName string) string {
+	name := strings.TrimSuffix(serviceName, "-service")
+
+	// Pluralize the name (simple heuristic)
+	if !strings.HasSuffix(name, "s") {
+		name += "s"
+	}
+
+	return "/api/v1/" + name
+}
+
 // GetContractsDir returns the directory containing contracts
 func GetContractsDir(tsuboFilePath string) string {
 	return filepath.Dir(tsuboFilePath)
@@ -2,7 +2,6 @@ package planner
 
 import (
 	"os"
-	"path/filepath"
 
 	"github.com/staka121/potter/internal/analyzer"
 	"github.com/staka121/potter/pkg/types"
@@ -13,37 +12,36 @@ func GeneratePlan(
 	tsubo *types.TsuboDefinition,
 	tsuboFile string,
 	contractsDir string,
-	projectRoot string,
+	config *types.ProjectConfig,
 	objects []analyzer.ObjectWithDeps,
-) *types.ImplementationPlan {
+) (*types.ImplementationPlan, error) {
 	// Get context files
-	contextFiles := GetContextFiles(projectRoot)
+	contextFiles := GetContextFiles(config.ContextFiles)
 
 	// Generate waves
-	waves := GenerateWaves(objects)
+	waves, err := GenerateWaves(objects)
+	if err != nil {
+		return nil, err
+	}
 
 	return &types.ImplementationPlan{
-		Tsubo:        tsubo.Tsubo.Name,
-		TsuboFile:    tsuboFile,
-		ContractsDir: contractsDir,
-		ProjectRoot:  projectRoot,
-		ContextFiles: contextFiles,
-		Waves:        waves,
-	}
+		Tsubo:              tsubo.Tsubo.Name,
+		TsuboFile:          tsuboFile,
+		ContractsDir:       contractsDir,
+		ProjectRoot:        config.ProjectRoot,
+		ImplementationsDir: config.ImplementationsDir,
+		ArtifactsDir:       config.ArtifactsDir,
+		ContextFiles:       contextFiles,
+		Network:            tsubo.Deployment.Network,
+		Waves:              waves,
+	}, nil
 }
 
-// GetContextFiles returns the list of context files that AI agents should read
-func GetContextFiles(projectRoot string) []string {
-	contextFiles := []string{
-		filepath.Join(projectRoot, "docs", "PHILOSOPHY.md"),
-		filepath.Join(projectRoot, "docs", "DEVELOPMENT_PRINCIPLES.md"),
-		filepath.Join(projectRoot, "docs", "WHY_GO.md"),
-		filepath.Join(projectRoot, "docs", "CONTRACT_DESIGN.md"),
-	}
-
-	// Filter out files that don't exist
+// GetContextFiles returns the context files that AI agents should read,
+// skipping any that don't exist
+func GetContextFiles(candidates []string) []string {
 	var existing []string
-	for _, file := range contextFiles {
+	for _, file := range candidates {
 		if _, err := os.Stat(file); err == nil {
 			existing = append(existing, file)
 		}
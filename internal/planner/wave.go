@@ -2,15 +2,57 @@ package planner
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/staka121/potter/internal/analyzer"
 	"github.com/staka121/potter/pkg/types"
 )
 
-// GenerateWaves creates implementation waves based on dependencies using topological sort
-func GenerateWaves(objects []analyzer.ObjectWithDeps) []types.Wave {
+// CycleError reports circular dependencies between objects
+type CycleError struct {
+	// Cycles holds one path per strongly connected component, starting and
+	// ending with the same object (a → b → c → a)
+	Cycles [][]string
+	// Members holds every object of each component, in the same order as Cycles
+	Members [][]string
+}
+
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	b.WriteString("circular dependencies detected:")
+	for i, cycle := range e.Cycles {
+		b.WriteString("\n  ")
+		b.WriteString(strings.Join(cycle, " → "))
+		if len(e.Members[i]) > len(cycle)-1 {
+			b.WriteString(fmt.Sprintf(" (cycle group: %s)", strings.Join(e.Members[i], ", ")))
+		}
+	}
+	return b.String()
+}
+
+// GenerateWaves creates implementation waves based on dependencies using topological sort.
+// It fails if the dependency graph has cycles or references unknown objects.
+func GenerateWaves(objects []analyzer.ObjectWithDeps) ([]types.Wave, error) {
+	objMap := make(map[string]analyzer.ObjectWithDeps)
+	for _, obj := range objects {
+		objMap[obj.Name] = obj
+	}
+
+	for _, obj := range objects {
+		for _, dep := range obj.Dependencies {
+			if _, exists := objMap[dep]; !exists {
+				return nil, fmt.Errorf("object %s depends on unknown object %s", obj.Name, dep)
+			}
+		}
+	}
+
+	if err := detectCycles(objects, objMap); err != nil {
+		return nil, err
+	}
+
 	// Calculate depth for each object based on dependency graph
-	depths := calculateDepths(objects)
+	depths := calculateDepths(objects, objMap)
 
 	// Group objects by depth into waves
 	waveMap := make(map[int][]types.ObjectInWave)
@@ -28,6 +70,7 @@ func GenerateWaves(objects []analyzer.ObjectWithDeps) []types.Wave {
 			Dependencies: obj.Dependencies,
 			Port:         obj.Port,
 			IsGateway:    obj.IsGateway,
+			Routes:       obj.Routes,
 		}
 
 		waveMap[depth] = append(waveMap[depth], objInWave)
@@ -45,83 +88,154 @@ func GenerateWaves(objects []analyzer.ObjectWithDeps) []types.Wave {
 		}
 	}
 
-	return waves
+	return waves, nil
 }
 
-// calculateDepths computes the depth of each object in the dependency graph
-// Depth = longest path from any root node (object with no dependencies)
-func calculateDepths(objects []analyzer.ObjectWithDeps) map[string]int {
-	depths := make(map[string]int)
-	visited := make(map[string]bool)
-	inProgress := make(map[string]bool)
+// detectCycles finds strongly connected components (Tarjan's algorithm) and
+// returns a CycleError describing every component that forms a cycle
+func detectCycles(objects []analyzer.ObjectWithDeps, objMap map[string]analyzer.ObjectWithDeps) error {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var strongConnect func(name string)
+	strongConnect = func(name string) {
+		index[name] = next
+		lowlink[name] = next
+		next++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, dep := range objMap[name].Dependencies {
+			if _, visited := index[dep]; !visited {
+				strongConnect(dep)
+				lowlink[name] = min(lowlink[name], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[name] = min(lowlink[name], index[dep])
+			}
+		}
+
+		if lowlink[name] == index[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
 
-	// Build object map for quick lookup
-	objMap := make(map[string]analyzer.ObjectWithDeps)
 	for _, obj := range objects {
-		objMap[obj.Name] = obj
+		if _, visited := index[obj.Name]; !visited {
+			strongConnect(obj.Name)
+		}
 	}
 
-	// Calculate depth for each object using DFS
-	var calculateDepth func(string) (int, error)
-	calculateDepth = func(name string) (int, error) {
-		// Check for cycles
-		if inProgress[name] {
-			return 0, fmt.Errorf("circular dependency detected: %s", name)
+	cycleErr := &CycleError{}
+	for _, component := range components {
+		sort.Strings(component)
+		start := component[0]
+		if len(component) == 1 && !dependsOn(objMap[start], start) {
+			continue
 		}
+		cycleErr.Cycles = append(cycleErr.Cycles, shortestCycle(start, component, objMap))
+		cycleErr.Members = append(cycleErr.Members, component)
+	}
 
-		// Return cached result
-		if visited[name] {
-			return depths[name], nil
+	if len(cycleErr.Cycles) == 0 {
+		return nil
+	}
+
+	sort.Slice(cycleErr.Cycles, func(i, j int) bool { return cycleErr.Cycles[i][0] < cycleErr.Cycles[j][0] })
+	sort.Slice(cycleErr.Members, func(i, j int) bool { return cycleErr.Members[i][0] < cycleErr.Members[j][0] })
+	return cycleErr
+}
+
+// shortestCycle returns the shortest path from start back to itself within a component
+func shortestCycle(start string, component []string, objMap map[string]analyzer.ObjectWithDeps) []string {
+	inComponent := make(map[string]bool)
+	for _, name := range component {
+		inComponent[name] = true
+	}
+
+	// Breadth-first search from start's dependencies until start is reached again
+	parent := make(map[string]string)
+	queue := []string{start}
+	visited := map[string]bool{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range objMap[current].Dependencies {
+			if !inComponent[dep] {
+				continue
+			}
+			if dep == start {
+				path := []string{start}
+				for node := current; node != start; node = parent[node] {
+					path = append(path, node)
+				}
+				for i, j := 1, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return append(path, start)
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				parent[dep] = current
+				queue = append(queue, dep)
+			}
 		}
+	}
 
-		inProgress[name] = true
-		defer func() { inProgress[name] = false }()
+	return append(append([]string{}, component...), start)
+}
 
-		obj, exists := objMap[name]
-		if !exists {
-			// Dependency not found in the object list (external dependency)
-			// Treat as depth 0 (already implemented)
-			depths[name] = 0
-			visited[name] = true
-			return 0, nil
+// dependsOn reports whether obj lists name as a dependency
+func dependsOn(obj analyzer.ObjectWithDeps, name string) bool {
+	for _, dep := range obj.Dependencies {
+		if dep == name {
+			return true
 		}
+	}
+	return false
+}
 
-		// Base case: no dependencies
-		if len(obj.Dependencies) == 0 {
-			depths[name] = 0
-			visited[name] = true
-			return 0, nil
+// calculateDepths computes the depth of each object in the (acyclic) dependency graph
+// Depth = longest path from any root node (object with no dependencies)
+func calculateDepths(objects []analyzer.ObjectWithDeps, objMap map[string]analyzer.ObjectWithDeps) map[string]int {
+	depths := make(map[string]int)
+	visited := make(map[string]bool)
+
+	var calculateDepth func(string) int
+	calculateDepth = func(name string) int {
+		if visited[name] {
+			return depths[name]
 		}
 
-		// Recursive case: depth = max(dependency depths) + 1
-		maxDepth := -1
-		for _, dep := range obj.Dependencies {
-			depDepth, err := calculateDepth(dep)
-			if err != nil {
-				return 0, err
-			}
-			if depDepth > maxDepth {
-				maxDepth = depDepth
+		// Depth = max(dependency depths) + 1, or 0 without dependencies
+		depth := 0
+		for _, dep := range objMap[name].Dependencies {
+			if d := calculateDepth(dep) + 1; d > depth {
+				depth = d
 			}
 		}
 
-		depth := maxDepth + 1
 		depths[name] = depth
 		visited[name] = true
-		return depth, nil
+		return depth
 	}
 
-	// Calculate depth for all objects
 	for _, obj := range objects {
-		if !visited[obj.Name] {
-			_, err := calculateDepth(obj.Name)
-			if err != nil {
-				// In case of cycle, assign to wave 0 (fallback)
-				// This shouldn't happen with proper contract validation
-				fmt.Printf("Warning: %v\n", err)
-				depths[obj.Name] = 0
-			}
-		}
+		calculateDepth(obj.Name)
 	}
 
 	return depths
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeDirName is the directory under the implementations directory that
+// holds the docker-compose.yml of the provisioned databases
+const ComposeDirName = "potter-databases"
+
+// Compose renders a docker-compose.yml that runs every instance on the tsubo
+// network, each with a healthcheck and a named volume for its data
+func Compose(instances []Instance, network string) ([]byte, error) {
+	services := &yaml.Node{Kind: yaml.MappingNode}
+	volumes := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, inst := range instances {
+		engine := inst.Engine()
+		volume := inst.Host + "-data"
+
+		env := &yaml.Node{Kind: yaml.MappingNode}
+		for _, v := range engine.Env {
+			env.Content = append(env.Content, scalarNode(v.Name), scalarNode(v.Value))
+		}
+		for _, v := range inst.ContainerEnv() {
+			env.Content = append(env.Content, scalarNode(v.Name), scalarNode(v.Value))
+		}
+
+		service := mappingNode(
+			"image", scalarNode(engine.Image),
+			"container_name", scalarNode(inst.Host),
+			"restart", scalarNode("unless-stopped"),
+			"environment", env,
+		)
+		if cmd := inst.Command(); cmd != nil {
+			service.Content = append(service.Content, scalarNode("command"), flowSequenceNode(escapeAll(cmd)...))
+		}
+		service.Content = append(service.Content,
+			scalarNode("healthcheck"), mappingNode(
+				"test", flowSequenceNode("CMD-SHELL", escape(engine.HealthCheck)),
+				"interval", scalarNode("5s"),
+				"timeout", scalarNode("5s"),
+				"retries", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "20"},
+			),
+			scalarNode("volumes"), sequenceNode(fmt.Sprintf("%s:%s", volume, engine.DataDir)),
+			scalarNode("networks"), sequenceNode(network),
+		)
+
+		services.Content = append(services.Content, scalarNode(inst.Host), service)
+		volumes.Content = append(volumes.Content, scalarNode(volume), &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle})
+	}
+
+	doc := mappingNode(
+		"services", services,
+		"networks", mappingNode(network, mappingNode("external", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})),
+		"volumes", volumes,
+	)
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by potter run from the databases declared in the contracts. Do not edit.\n")
+	enc := yaml.NewEncoder(&sb)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to render database compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to render database compose file: %w", err)
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// escape protects "$" from docker compose variable interpolation
+func escape(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeAll(values []string) []string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = escape(v)
+	}
+	return escaped
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func mappingNode(pairs ...interface{}) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		node.Content = append(node.Content, scalarNode(pairs[i].(string)), pairs[i+1].(*yaml.Node))
+	}
+	return node
+}
+
+func sequenceNode(items ...string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range items {
+		node.Content = append(node.Content, scalarNode(item))
+	}
+	return node
+}
+
+func flowSequenceNode(items ...string) *yaml.Node {
+	node := sequenceNode(items...)
+	node.Style = yaml.FlowStyle
+	return node
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// Engine describes how Potter runs one database type
+type Engine struct {
+	Image       string
+	Port        int
+	Driver      string   // Go driver the implementation should use
+	DataDir     string   // Where the container keeps its data
+	Env         []EnvVar // Fixed container settings (credentials come from Instance.ContainerEnv)
+	HealthCheck string   // Shell command that succeeds once the database accepts connections
+}
+
+// engines lists the database types Potter provisions. Other types (e.g. in-memory)
+// are left to the implementation.
+var engines = map[string]Engine{
+	"postgres": {
+		Image:       "postgres:16-alpine",
+		Port:        5432,
+		Driver:      "github.com/jackc/pgx/v5",
+		DataDir:     "/var/lib/postgresql/data",
+		Env:         []EnvVar{{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"}}, // volume roots may contain lost+found
+		HealthCheck: `pg_isready -U "$POSTGRES_USER" -d "$POSTGRES_DB"`,
+	},
+	"mysql": {
+		Image:       "mysql:8.4",
+		Port:        3306,
+		Driver:      "github.com/go-sql-driver/mysql",
+		DataDir:     "/var/lib/mysql",
+		HealthCheck: `mysqladmin ping -h 127.0.0.1 -u"$MYSQL_USER" -p"$MYSQL_PASSWORD" --silent`,
+	},
+	"redis": {
+		Image:       "redis:7-alpine",
+		Port:        6379,
+		Driver:      "github.com/redis/go-redis/v9",
+		DataDir:     "/data",
+		HealthCheck: `redis-cli -a "$REDIS_PASSWORD" --no-auth-warning ping | grep -q PONG`,
+	},
+}
+
+// Supported reports whether Potter provisions databases of the given type
+func Supported(dbType string) bool {
+	_, ok := engines[dbType]
+	return ok
+}
+
+// SupportedTypes returns the provisioned database types in name order
+func SupportedTypes() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Instance is a database provisioned for one service. Every service gets its
+// own server, database and credentials, so services never share storage.
+type Instance struct {
+	Service  string
+	Name     string // Name declared in the contract
+	Type     string
+	Tables   []string
+	Host     string // Container and Kubernetes service name: <service>-<name>
+	Database string
+	User     string
+	Password string // Empty until credentials are assigned
+}
+
+// ForService returns the provisioned databases among a service's declared dependencies
+func ForService(service string, deps []types.DatabaseDependency) []Instance {
+	var instances []Instance
+	for _, dep := range deps {
+		if !Supported(dep.Type) {
+			continue
+		}
+		instances = append(instances, Instance{
+			Service:  service,
+			Name:     dep.Name,
+			Type:     dep.Type,
+			Tables:   dep.Tables,
+			Host:     dnsName(service + "-" + dep.Name),
+			Database: identifier(dep.Name),
+			User:     identifier(service),
+		})
+	}
+	return instances
+}
+
+// ForTsubo returns the provisioned databases of every object in the tsubo, keyed by object name
+func ForTsubo(tsubo *types.TsuboDefinition, contractsDir string) (map[string][]Instance, error) {
+	databases := make(map[string][]Instance)
+	for _, objRef := range tsubo.Objects {
+		objectDef, err := parser.ParseObjectFile(filepath.Join(contractsDir, objRef.Contract))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", objRef.Contract, err)
+		}
+		if instances := ForService(objRef.Name, objectDef.Dependencies.Databases); len(instances) > 0 {
+			databases[objRef.Name] = instances
+		}
+	}
+	return databases, nil
+}
+
+// AssignCredentials sets the password of every instance from secret, which
+// returns a stable generated value for a key (see state.Manager.Secret)
+func AssignCredentials(instances []Instance, secret func(key string) (string, error)) error {
+	for i := range instances {
+		password, err := secret(instances[i].SecretKey())
+		if err != nil {
+			return err
+		}
+		instances[i].Password = password
+	}
+	return nil
+}
+
+// SecretKey identifies the instance's credentials in the secret store
+func (i Instance) SecretKey() string {
+	return "database/" + i.Service + "/" + i.Name
+}
+
+// Engine returns how the instance is run
+func (i Instance) Engine() Engine {
+	return engines[i.Type]
+}
+
+// EnvVar is the environment variable holding the connection string, e.g. USER_DB_URL
+func (i Instance) EnvVar() string {
+	return strings.ToUpper(identifier(i.Name)) + "_URL"
+}
+
+// URL returns the connection string for reaching the database at host, in the
+// format expected by the engine's Go driver
+func (i Instance) URL(host string) string {
+	port := i.Engine().Port
+	switch i.Type {
+	case "postgres":
+		return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable", i.User, i.Password, host, port, i.Database)
+	case "mysql":
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", i.User, i.Password, host, port, i.Database)
+	case "redis":
+		return fmt.Sprintf("redis://:%s@%s:%d/0", i.Password, host, port)
+	}
+	return ""
+}
+
+// ContainerEnv returns the environment that initializes the database container
+// with the instance's database and credentials
+func (i Instance) ContainerEnv() []EnvVar {
+	switch i.Type {
+	case "postgres":
+		return []EnvVar{
+			{Name: "POSTGRES_DB", Value: i.Database},
+			{Name: "POSTGRES_USER", Value: i.User},
+			{Name: "POSTGRES_PASSWORD", Value: i.Password},
+		}
+	case "mysql":
+		return []EnvVar{
+			{Name: "MYSQL_DATABASE", Value: i.Database},
+			{Name: "MYSQL_USER", Value: i.User},
+			{Name: "MYSQL_PASSWORD", Value: i.Password},
+			{Name: "MYSQL_ROOT_PASSWORD", Value: i.Password},
+		}
+	case "redis":
+		return []EnvVar{
+			{Name: "REDIS_PASSWORD", Value: i.Password},
+		}
+	}
+	return nil
+}
+
+// Command overrides the container command when the image needs it (redis has no password variable)
+func (i Instance) Command() []string {
+	if i.Type == "redis" {
+		return []string{"sh", "-c", `exec redis-server --requirepass "$REDIS_PASSWORD" --appendonly yes`}
+	}
+	return nil
+}
+
+// EnvVar is a name/value pair of an environment variable
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// dnsName turns a name into a valid container / Kubernetes service name
+func dnsName(name string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, name), "-")
+}
+
+// identifier turns a name into a database, user or environment variable identifier
+func identifier(name string) string {
+	return strings.ReplaceAll(dnsName(name), "-", "_")
+}
@@ -2,8 +2,9 @@ package diff
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 
 	"github.com/staka121/potter/internal/parser"
 	"github.com/staka121/potter/pkg/state"
@@ -51,7 +52,7 @@ func DetectChanges(
 	for name, obj := range currentServices {
 		contractPath := resolveContractPath(contractsDir, obj.Contract)
 
-		newHash, err := stateManager.ComputeHash(contractPath)
+		newHash, err := stateManager.ComputeContractHash(contractPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to hash contract for %s: %w", name, err)
 		}
@@ -75,7 +76,7 @@ func DetectChanges(
 		}
 
 		// Contract changed — classify as breaking or non-breaking
-		changeType, details, err := classifyChange(existingState.ContractSnapshot, contractPath)
+		changeType, details, err := classifyChange(existingState, contractPath)
 		if err != nil {
 			// If we can't parse, conservatively treat as breaking
 			changeType = "modified_breaking"
@@ -106,18 +107,16 @@ func resolveContractPath(contractsDir, contractRef string) string {
 }
 
 // classifyChange determines whether a contract change is breaking or non-breaking
-func classifyChange(oldSnapshot, newContractPath string) (changeType string, details []string, err error) {
-	newData, err := os.ReadFile(newContractPath)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to read new contract: %w", err)
-	}
-
-	oldObj, err := parser.ParseObjectYAML([]byte(oldSnapshot))
+func classifyChange(oldState *types.ServiceState, newContractPath string) (changeType string, details []string, err error) {
+	oldObj, err := parser.ParseObjectYAML([]byte(oldState.ContractSnapshot))
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to parse old contract: %w", err)
 	}
+	if err := parser.ResolveImportsFromSnapshots(oldObj, oldState.ImportSnapshots); err != nil {
+		return "", nil, err
+	}
 
-	newObj, err := parser.ParseObjectYAML(newData)
+	newObj, err := parser.ParseObjectFile(newContractPath)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to parse new contract: %w", err)
 	}
@@ -156,6 +155,13 @@ func classifyChange(oldSnapshot, newContractPath string) (changeType string, det
 			isBreaking = true
 			details = append(details, fmt.Sprintf("Breaking request change in endpoint: %s %s", newEp.Method, newEp.Path))
 		}
+
+		// Check for changes in endpoint semantics (edge cases and intent)
+		semBreaking, semDetails := compareSemantics(oldEp, newEp)
+		if semBreaking {
+			isBreaking = true
+		}
+		details = append(details, semDetails...)
 	}
 
 	// Check for type changes (simplified: any type removal is breaking)
@@ -166,6 +172,13 @@ func classifyChange(oldSnapshot, newContractPath string) (changeType string, det
 		}
 	}
 
+	// Check for changes in imported type libraries
+	importBreaking, importDetails := compareImportedTypes(oldObj.ImportedTypes, newObj.ImportedTypes)
+	if importBreaking {
+		isBreaking = true
+	}
+	details = append(details, importDetails...)
+
 	if len(details) == 0 {
 		details = append(details, "Contract updated (description or metadata changes)")
 	}
@@ -200,6 +213,117 @@ func hasBreakingRequestChange(oldReq, newReq map[string]interface{}) bool {
 	return false
 }
 
+// compareSemantics compares endpoint semantics.
+// A removed edge case or a changed edge case status is breaking; other changes are not.
+func compareSemantics(oldEp, newEp types.Endpoint) (breaking bool, details []string) {
+	route := fmt.Sprintf("%s %s", newEp.Method, newEp.Path)
+
+	oldCases := make(map[string]types.EdgeCase)
+	for _, ec := range oldEp.Semantics.Behavior.EdgeCases {
+		oldCases[ec.Case] = ec
+	}
+	newCases := make(map[string]types.EdgeCase)
+	for _, ec := range newEp.Semantics.Behavior.EdgeCases {
+		newCases[ec.Case] = ec
+	}
+
+	for _, oldEc := range oldEp.Semantics.Behavior.EdgeCases {
+		newEc, exists := newCases[oldEc.Case]
+		if !exists {
+			breaking = true
+			details = append(details, fmt.Sprintf("Edge case removed in %s: %s", route, oldEc.Case))
+			continue
+		}
+		if oldEc.Status != newEc.Status {
+			breaking = true
+			details = append(details, fmt.Sprintf("Edge case status changed in %s: %s (%d → %d)", route, oldEc.Case, oldEc.Status, newEc.Status))
+		}
+	}
+
+	for _, newEc := range newEp.Semantics.Behavior.EdgeCases {
+		if _, existed := oldCases[newEc.Case]; !existed {
+			details = append(details, fmt.Sprintf("Edge case added in %s: %s", route, newEc.Case))
+		}
+	}
+
+	if oldEp.Semantics.Intent != newEp.Semantics.Intent || oldEp.Semantics.Behavior.Success != newEp.Semantics.Behavior.Success {
+		details = append(details, fmt.Sprintf("Semantics updated in %s", route))
+	}
+
+	return breaking, details
+}
+
+// compareImportedTypes compares the types of imported libraries.
+// A removed type, a removed property or a newly required property is breaking;
+// other changes to a shared type are not.
+func compareImportedTypes(oldLibs, newLibs map[string]map[string]types.TypeDef) (breaking bool, details []string) {
+	for _, file := range sortedKeys(oldLibs) {
+		oldTypes := oldLibs[file]
+		newTypes := newLibs[file]
+		for _, name := range sortedKeys(oldTypes) {
+			oldDef := oldTypes[name]
+			newDef, exists := newTypes[name]
+			if !exists {
+				breaking = true
+				details = append(details, fmt.Sprintf("Imported type removed: %s#/types/%s", file, name))
+				continue
+			}
+			if reflect.DeepEqual(oldDef, newDef) {
+				continue
+			}
+			if isBreakingTypeChange(oldDef, newDef) {
+				breaking = true
+				details = append(details, fmt.Sprintf("Breaking change in imported type: %s#/types/%s", file, name))
+			} else {
+				details = append(details, fmt.Sprintf("Imported type changed: %s#/types/%s", file, name))
+			}
+		}
+	}
+
+	for _, file := range sortedKeys(newLibs) {
+		if _, existed := oldLibs[file]; !existed {
+			details = append(details, fmt.Sprintf("Type library imported: %s", file))
+			continue
+		}
+		for _, name := range sortedKeys(newLibs[file]) {
+			if _, existed := oldLibs[file][name]; !existed {
+				details = append(details, fmt.Sprintf("Imported type added: %s#/types/%s", file, name))
+			}
+		}
+	}
+
+	return breaking, details
+}
+
+// isBreakingTypeChange reports whether a type lost a property or gained a required property
+func isBreakingTypeChange(oldDef, newDef types.TypeDef) bool {
+	for prop := range oldDef.Properties {
+		if _, exists := newDef.Properties[prop]; !exists {
+			return true
+		}
+	}
+
+	oldRequired := make(map[string]bool, len(oldDef.Required))
+	for _, r := range oldDef.Required {
+		oldRequired[r] = true
+	}
+	for _, r := range newDef.Required {
+		if !oldRequired[r] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // extractRequired extracts field names from a request schema map
 func extractRequired(req map[string]interface{}) (required []string, optional []string) {
 	for key, val := range req {
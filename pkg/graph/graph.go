@@ -0,0 +1,203 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/staka121/potter/internal/analyzer"
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/internal/planner"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// Node kinds
+const (
+	KindService  = "service"
+	KindGateway  = "gateway"
+	KindDatabase = "database"
+	KindExternal = "external" // Service declared external: true
+)
+
+// Graph is the dependency graph of a tsubo
+type Graph struct {
+	Tsubo string `json:"tsubo"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+	Waves []Wave `json:"waves"`
+
+	// Highlight is the service whose transitive dependents are highlighted (empty if none)
+	Highlight string `json:"highlight,omitempty"`
+	// Cycles lists circular dependencies; waves are empty when there are any
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+// Node is a service, the gateway, a database or an external service
+type Node struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Port        int    `json:"port,omitempty"`
+	Wave        *int   `json:"wave,omitempty"`    // Implementation wave (services and gateway only)
+	DBType      string `json:"db_type,omitempty"` // Database engine (databases only)
+	Highlighted bool   `json:"highlighted,omitempty"`
+}
+
+// Edge points from a dependent to its dependency
+type Edge struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Kind        string   `json:"kind"`                // Kind of the target node
+	Endpoints   []string `json:"endpoints,omitempty"` // Endpoints used (service dependencies)
+	Tables      []string `json:"tables,omitempty"`    // Tables used (database dependencies)
+	Highlighted bool     `json:"highlighted,omitempty"`
+}
+
+// Wave groups the objects implemented together
+type Wave struct {
+	Wave    int      `json:"wave"`
+	Objects []string `json:"objects"`
+}
+
+// DatabaseID returns the node ID of a database (databases share a namespace with services)
+func DatabaseID(name string) string {
+	return "db:" + name
+}
+
+// Build creates the dependency graph of a tsubo from its contracts
+func Build(tsubo *types.TsuboDefinition, contractsDir string) (*Graph, error) {
+	objects, err := analyzer.AnalyzeDependencies(tsubo, contractsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{Tsubo: tsubo.Tsubo.Name}
+
+	waves, err := planner.GenerateWaves(objects)
+	var cycleErr *planner.CycleError
+	switch {
+	case errors.As(err, &cycleErr):
+		g.Cycles = cycleErr.Cycles
+	case err != nil:
+		return nil, err
+	}
+
+	waveOf := make(map[string]int)
+	for _, wave := range waves {
+		w := Wave{Wave: wave.Wave}
+		for _, obj := range wave.Objects {
+			w.Objects = append(w.Objects, obj.Name)
+			waveOf[obj.Name] = wave.Wave
+		}
+		g.Waves = append(g.Waves, w)
+	}
+
+	contracts := make(map[string]*types.ObjectDefinition)
+	for _, objRef := range tsubo.Objects {
+		objectDef, err := parser.ParseObjectFile(filepath.Join(contractsDir, objRef.Contract))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", objRef.Contract, err)
+		}
+		contracts[objRef.Name] = objectDef
+	}
+
+	seen := make(map[string]bool)
+	addNode := func(n Node) {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	for _, obj := range objects {
+		node := Node{ID: obj.Name, Kind: KindService, Port: obj.Port}
+		if obj.IsGateway {
+			node.Kind = KindGateway
+		}
+		if wave, ok := waveOf[obj.Name]; ok {
+			node.Wave = &wave
+		}
+		addNode(node)
+
+		objectDef := contracts[obj.Name]
+		if obj.IsGateway || objectDef == nil {
+			for _, dep := range obj.Dependencies {
+				g.Edges = append(g.Edges, Edge{From: obj.Name, To: dep, Kind: KindService})
+			}
+			continue
+		}
+
+		for _, dep := range objectDef.Dependencies.Services {
+			kind := KindService
+			if dep.External {
+				kind = KindExternal
+				addNode(Node{ID: dep.Name, Kind: KindExternal})
+			}
+			g.Edges = append(g.Edges, Edge{From: obj.Name, To: dep.Name, Kind: kind, Endpoints: dep.Endpoints})
+		}
+
+		for _, db := range objectDef.Dependencies.Databases {
+			id := DatabaseID(db.Name)
+			addNode(Node{ID: id, Kind: KindDatabase, DBType: db.Type})
+			g.Edges = append(g.Edges, Edge{From: obj.Name, To: id, Kind: KindDatabase, Tables: db.Tables})
+		}
+	}
+
+	return g, nil
+}
+
+// Dependents returns every node that depends on name, directly or transitively (sorted)
+func (g *Graph) Dependents(name string) []string {
+	reverse := make(map[string][]string)
+	for _, e := range g.Edges {
+		reverse[e.To] = append(reverse[e.To], e.From)
+	}
+
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	var dependents []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[current] {
+			if !visited[from] {
+				visited[from] = true
+				dependents = append(dependents, from)
+				queue = append(queue, from)
+			}
+		}
+	}
+
+	sort.Strings(dependents)
+	return dependents
+}
+
+// HighlightDependents marks a node and its transitive dependents, along with
+// the edges between them
+func (g *Graph) HighlightDependents(name string) error {
+	target := ""
+	for _, id := range []string{name, DatabaseID(name)} {
+		for _, n := range g.Nodes {
+			if target == "" && n.ID == id {
+				target = id
+			}
+		}
+	}
+	if target == "" {
+		return fmt.Errorf("%s is not a service or database in the graph", name)
+	}
+
+	marked := map[string]bool{target: true}
+	for _, dep := range g.Dependents(target) {
+		marked[dep] = true
+	}
+
+	for i := range g.Nodes {
+		g.Nodes[i].Highlighted = marked[g.Nodes[i].ID]
+	}
+	for i := range g.Edges {
+		g.Edges[i].Highlighted = marked[g.Edges[i].From] && marked[g.Edges[i].To]
+	}
+	g.Highlight = target
+	return nil
+}
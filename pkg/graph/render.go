@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Render encodes the graph as "dot", "mermaid" or "json"
+func Render(g *Graph, format string) ([]byte, error) {
+	switch format {
+	case "dot", "":
+		return []byte(DOT(g)), nil
+	case "mermaid":
+		return []byte(Mermaid(g)), nil
+	case "json":
+		data, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode graph JSON: %w", err)
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected dot, mermaid or json)", format)
+	}
+}
+
+// highlightColor is used for highlighted nodes and edges
+const highlightColor = "#d62728"
+
+// DOT renders the graph in Graphviz DOT format
+func DOT(g *Graph) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("digraph %q {\n", g.Tsubo))
+	b.WriteString("  rankdir=RL;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=white, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	clustered := make(map[string]bool)
+	for _, wave := range g.Waves {
+		b.WriteString(fmt.Sprintf("\n  subgraph cluster_wave_%d {\n", wave.Wave))
+		b.WriteString(fmt.Sprintf("    label=\"Wave %d\";\n", wave.Wave))
+		b.WriteString("    style=dashed;\n")
+		for _, name := range wave.Objects {
+			b.WriteString("    " + dotNode(g.node(name)) + "\n")
+			clustered[name] = true
+		}
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("\n")
+	for _, n := range g.Nodes {
+		if !clustered[n.ID] {
+			b.WriteString("  " + dotNode(n) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	for _, e := range g.Edges {
+		var attrs []string
+		if label := edgeLabel(e, "\n"); label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		}
+		switch e.Kind {
+		case KindDatabase, KindExternal:
+			attrs = append(attrs, "style=dashed")
+		}
+		if e.Highlighted {
+			attrs = append(attrs, fmt.Sprintf("color=%q", highlightColor), fmt.Sprintf("fontcolor=%q", highlightColor), "penwidth=2")
+		}
+		b.WriteString(fmt.Sprintf("  %q -> %q", e.From, e.To))
+		if len(attrs) > 0 {
+			b.WriteString(" [" + strings.Join(attrs, ", ") + "]")
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotNode(n Node) string {
+	// %q escapes newlines as \n, which DOT renders as line breaks
+	attrs := []string{fmt.Sprintf("label=%q", nodeLabel(n))}
+	switch n.Kind {
+	case KindGateway:
+		attrs = append(attrs, "shape=hexagon")
+	case KindDatabase:
+		attrs = append(attrs, "shape=cylinder")
+	case KindExternal:
+		attrs = append(attrs, "style=\"rounded,dashed\"")
+	}
+	if n.Highlighted {
+		attrs = append(attrs, fmt.Sprintf("color=%q", highlightColor), "fillcolor=\"#fde0dd\"", "penwidth=2")
+	}
+	return fmt.Sprintf("%q [%s];", n.ID, strings.Join(attrs, ", "))
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func Mermaid(g *Graph) string {
+	var b strings.Builder
+
+	b.WriteString("flowchart RL\n")
+
+	clustered := make(map[string]bool)
+	for _, wave := range g.Waves {
+		b.WriteString(fmt.Sprintf("  subgraph wave_%d[\"Wave %d\"]\n", wave.Wave, wave.Wave))
+		for _, name := range wave.Objects {
+			b.WriteString("    " + mermaidNode(g.node(name)) + "\n")
+			clustered[name] = true
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, n := range g.Nodes {
+		if !clustered[n.ID] {
+			b.WriteString("  " + mermaidNode(n) + "\n")
+		}
+	}
+
+	var highlightedLinks []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == KindDatabase || e.Kind == KindExternal {
+			arrow = "-.->"
+		}
+		if label := edgeLabel(e, "<br/>"); label != "" {
+			if arrow == "-->" {
+				arrow = fmt.Sprintf("-->|%q|", label)
+			} else {
+				arrow = fmt.Sprintf("-.->|%q|", label)
+			}
+		}
+		b.WriteString(fmt.Sprintf("  %s %s %s\n", mermaidID(e.From), arrow, mermaidID(e.To)))
+		if e.Highlighted {
+			highlightedLinks = append(highlightedLinks, fmt.Sprintf("%d", i))
+		}
+	}
+
+	var highlighted []string
+	for _, n := range g.Nodes {
+		if n.Highlighted {
+			highlighted = append(highlighted, mermaidID(n.ID))
+		}
+	}
+	if len(highlighted) > 0 {
+		b.WriteString(fmt.Sprintf("  classDef highlight fill:#fde0dd,stroke:%s,stroke-width:2px\n", highlightColor))
+		b.WriteString(fmt.Sprintf("  class %s highlight\n", strings.Join(highlighted, ",")))
+	}
+	if len(highlightedLinks) > 0 {
+		b.WriteString(fmt.Sprintf("  linkStyle %s stroke:%s,stroke-width:2px\n", strings.Join(highlightedLinks, ","), highlightColor))
+	}
+
+	return b.String()
+}
+
+func mermaidNode(n Node) string {
+	label := strings.ReplaceAll(nodeLabel(n), "\n", "<br/>")
+	id := mermaidID(n.ID)
+	switch n.Kind {
+	case KindGateway:
+		return fmt.Sprintf("%s{{%q}}", id, label)
+	case KindDatabase:
+		return fmt.Sprintf("%s[(%q)]", id, label)
+	case KindExternal:
+		return fmt.Sprintf("%s>%q]", id, label)
+	}
+	return fmt.Sprintf("%s[%q]", id, label)
+}
+
+// mermaidIDPattern matches characters that are not allowed in Mermaid node IDs
+var mermaidIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func mermaidID(id string) string {
+	return mermaidIDPattern.ReplaceAllString(id, "_")
+}
+
+// node returns the node with the given ID
+func (g *Graph) node(id string) Node {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return Node{ID: id, Kind: KindService}
+}
+
+func nodeLabel(n Node) string {
+	switch n.Kind {
+	case KindDatabase:
+		name := strings.TrimPrefix(n.ID, "db:")
+		if n.DBType != "" {
+			return fmt.Sprintf("%s\n(%s)", name, n.DBType)
+		}
+		return name
+	case KindExternal:
+		return n.ID + "\n(external)"
+	}
+	if n.Port > 0 {
+		return fmt.Sprintf("%s\n:%d", n.ID, n.Port)
+	}
+	return n.ID
+}
+
+func edgeLabel(e Edge, sep string) string {
+	items := e.Endpoints
+	if e.Kind == KindDatabase {
+		items = e.Tables
+	}
+	items = append([]string(nil), items...)
+	sort.Strings(items)
+	return strings.Join(items, sep)
+}
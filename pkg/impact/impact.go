@@ -0,0 +1,276 @@
+package impact
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/internal/analyzer"
+	"github.com/staka121/potter/internal/parser"
+	"github.com/staka121/potter/pkg/graph"
+	"github.com/staka121/potter/pkg/k8s"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// Report is the blast radius of changing a service (or one of its endpoints)
+type Report struct {
+	Tsubo      string           `json:"tsubo"`
+	Service    string           `json:"service"`
+	Endpoint   *Endpoint        `json:"endpoint,omitempty"`
+	Dependents []Dependent      `json:"dependents"`
+	Routes     []Route          `json:"routes"`
+	Monitoring []MonitoringRule `json:"monitoring"`
+	EnvVars    []EnvVar         `json:"env_vars"`
+}
+
+// Endpoint is the endpoint the analysis is narrowed to
+type Endpoint struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Path   string `json:"path"` // Including the contract's base_path
+}
+
+// Dependent is an object affected by the change
+type Dependent struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`  // graph node kind: service or gateway
+	Depth  int    `json:"depth"` // 1 = depends on the service directly
+	Direct bool   `json:"direct"`
+	// Via is the affected dependency through which a transitive dependent is reached
+	Via string `json:"via,omitempty"`
+	// Endpoints are the references to the service declared by a direct dependent
+	Endpoints []string `json:"endpoints,omitempty"`
+	// Undeclared is set when a direct dependent does not list the endpoints it
+	// uses, so it is kept as possibly affected
+	Undeclared bool `json:"undeclared,omitempty"`
+}
+
+// Route is a gateway / Ingress route serving an affected object
+type Route struct {
+	Service string `json:"service"`
+	Path    string `json:"path"`
+}
+
+// MonitoringRule is a monitoring manifest (potter monitor generate) of an affected object
+type MonitoringRule struct {
+	Object string   `json:"object"`
+	Kind   string   `json:"kind"` // ServiceMonitor or PrometheusRule
+	Name   string   `json:"name"`
+	Alerts []string `json:"alerts,omitempty"`
+}
+
+// EnvVar is a Kubernetes environment variable that points at the service
+type EnvVar struct {
+	Object string `json:"object"` // Deployment that sets the variable
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+}
+
+// Analyze computes which objects, routes, monitoring rules and Kubernetes
+// environment variables are affected by a change to service. With an endpoint
+// reference (ID, "METHOD /path" or path), direct dependents are narrowed to
+// those whose declared endpoints reference it.
+func Analyze(tsubo *types.TsuboDefinition, contractsDir, service, endpointRef, namespace string) (*Report, error) {
+	contracts := make(map[string]*types.ObjectDefinition)
+	var names []string
+	for _, objRef := range tsubo.Objects {
+		objectDef, err := parser.ParseObjectFile(filepath.Join(contractsDir, objRef.Contract))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", objRef.Contract, err)
+		}
+		contracts[objRef.Name] = objectDef
+		names = append(names, objRef.Name)
+	}
+
+	target, ok := contracts[service]
+	if !ok {
+		msg := fmt.Sprintf("%s is not an object in the tsubo", service)
+		if suggestion := analyzer.SuggestName(service, names); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
+		return nil, errors.New(msg)
+	}
+
+	g, err := graph.Build(tsubo, contractsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	// Empty lists are encoded as [] rather than null for JSON consumers
+	report := &Report{
+		Tsubo:      tsubo.Tsubo.Name,
+		Service:    service,
+		Dependents: []Dependent{},
+		Routes:     []Route{},
+		Monitoring: []MonitoringRule{},
+		EnvVars:    []EnvVar{},
+	}
+
+	var endpoint *types.Endpoint
+	if endpointRef != "" {
+		endpoint = analyzer.FindEndpoint(target, endpointRef)
+		if endpoint == nil {
+			return nil, fmt.Errorf("endpoint %q is not defined in the %s contract", endpointRef, service)
+		}
+		report.Endpoint = &Endpoint{
+			ID:     endpoint.ID,
+			Method: strings.ToUpper(endpoint.Method),
+			Path:   joinPath(target.API.BasePath, endpoint.Path),
+		}
+	}
+
+	var routes []types.GatewayRoute
+	if parser.GatewayEnabled(tsubo) {
+		routes = parser.GatewayRoutes(tsubo, contractsDir)
+	}
+	targetPaths := routePaths(routes, service, report.Endpoint)
+
+	kinds := make(map[string]string)
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+
+	// Direct dependents, narrowed to the endpoint if one is given
+	affected := map[string]bool{service: true}
+	var queue []string
+	for _, e := range g.Edges {
+		if e.To != service || affected[e.From] {
+			continue
+		}
+		dep := Dependent{Name: e.From, Kind: kinds[e.From], Depth: 1, Direct: true, Endpoints: e.Endpoints}
+		if endpoint != nil {
+			switch {
+			case dep.Kind == graph.KindGateway:
+				// The gateway forwards the endpoint only if one of the service's routes covers it
+				if len(targetPaths) == 0 {
+					continue
+				}
+			case len(e.Endpoints) == 0:
+				dep.Undeclared = true
+			case !references(target, e.Endpoints, endpoint):
+				continue
+			}
+		}
+		affected[e.From] = true
+		report.Dependents = append(report.Dependents, dep)
+		queue = append(queue, e.From)
+	}
+
+	// Transitive dependents of the affected objects
+	reverse := make(map[string][]string)
+	for _, e := range g.Edges {
+		reverse[e.To] = append(reverse[e.To], e.From)
+	}
+	depth := map[string]int{service: 0}
+	for _, d := range report.Dependents {
+		depth[d.Name] = 1
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[current] {
+			if affected[from] {
+				continue
+			}
+			affected[from] = true
+			depth[from] = depth[current] + 1
+			report.Dependents = append(report.Dependents, Dependent{
+				Name:  from,
+				Kind:  kinds[from],
+				Depth: depth[from],
+				Via:   current,
+			})
+			queue = append(queue, from)
+		}
+	}
+
+	sort.SliceStable(report.Dependents, func(i, j int) bool {
+		a, b := report.Dependents[i], report.Dependents[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.Name < b.Name
+	})
+
+	// Gateway / Ingress routes of every affected object
+	for _, route := range routes {
+		if !affected[route.Service] || route.Exclude {
+			continue
+		}
+		paths := route.Paths
+		if route.Service == service {
+			paths = targetPaths
+		}
+		for _, path := range paths {
+			report.Routes = append(report.Routes, Route{Service: route.Service, Path: path})
+		}
+	}
+
+	// Monitoring manifests and environment variables of affected tsubo objects
+	for _, objRef := range tsubo.Objects {
+		if !affected[objRef.Name] {
+			continue
+		}
+
+		report.Monitoring = append(report.Monitoring, MonitoringRule{Object: objRef.Name, Kind: "ServiceMonitor", Name: objRef.Name})
+		if alerts := k8s.SLAAlerts(contracts[objRef.Name].Performance.Latency); len(alerts) > 0 {
+			report.Monitoring = append(report.Monitoring, MonitoringRule{
+				Object: objRef.Name,
+				Kind:   "PrometheusRule",
+				Name:   k8s.PrometheusRuleName(objRef.Name),
+				Alerts: alerts,
+			})
+		}
+
+		for _, dep := range objRef.Dependencies {
+			if dep == service {
+				report.EnvVars = append(report.EnvVars, EnvVar{
+					Object: objRef.Name,
+					Name:   k8s.ServiceEnvVar(service),
+					Value:  k8s.ServiceURL(service, namespace),
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// references reports whether any endpoint reference resolves to the given endpoint
+func references(contract *types.ObjectDefinition, refs []string, endpoint *types.Endpoint) bool {
+	for _, ref := range refs {
+		if analyzer.FindEndpoint(contract, ref) == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// routePaths returns the route paths of a service, narrowed to the ones that
+// cover the endpoint when one is given
+func routePaths(routes []types.GatewayRoute, service string, endpoint *Endpoint) []string {
+	var paths []string
+	for _, route := range routes {
+		if route.Service != service || route.Exclude {
+			continue
+		}
+		for _, path := range route.Paths {
+			if endpoint == nil || covers(path, endpoint.Path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// covers reports whether a route prefix matches a path on a segment boundary
+func covers(prefix, path string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func joinPath(basePath, path string) string {
+	return strings.TrimSuffix(basePath, "/") + "/" + strings.TrimPrefix(path, "/")
+}
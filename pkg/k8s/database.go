@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staka121/potter/pkg/database"
+)
+
+// GenerateDatabase generates the manifests of a provisioned database: a
+// headless Service and a StatefulSet whose data lives on a
+// PersistentVolumeClaim. Its credentials are in GenerateDatabaseSecret.
+func GenerateDatabase(inst database.Instance, config *GeneratorConfig, tsuboName string, labels map[string]string) string {
+	engine := inst.Engine()
+
+	var env strings.Builder
+	for _, v := range engine.Env {
+		env.WriteString(fmt.Sprintf("        - name: %s\n          value: %q\n", v.Name, v.Value))
+	}
+	for _, v := range inst.ContainerEnv() {
+		env.WriteString(fmt.Sprintf(`        - name: %s
+          valueFrom:
+            secretKeyRef:
+              name: %s
+              key: %s
+`, v.Name, inst.Host, v.Name))
+	}
+
+	command := ""
+	if cmd := inst.Command(); cmd != nil {
+		quoted := make([]string, len(cmd))
+		for i, c := range cmd {
+			quoted[i] = fmt.Sprintf("%q", c)
+		}
+		command = fmt.Sprintf("        command: [%s]\n", strings.Join(quoted, ", "))
+	}
+
+	metadata := databaseMetadata(inst, config, tsuboName, labels)
+
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+%sspec:
+  clusterIP: None
+  ports:
+  - port: %d
+    targetPort: %d
+    name: %s
+  selector:
+    app: %s
+---
+apiVersion: apps/v1
+kind: StatefulSet
+%sspec:
+  serviceName: %s
+  replicas: 1
+  selector:
+    matchLabels:
+      app: %s
+  template:
+    metadata:
+      labels:
+        app: %s
+        app.kubernetes.io/component: database
+    spec:
+      containers:
+      - name: %s
+        image: %s
+%s        ports:
+        - containerPort: %d
+          name: %s
+        env:
+%s        readinessProbe:
+          exec:
+            command: ["sh", "-c", %q]
+          initialDelaySeconds: 5
+          periodSeconds: 5
+        volumeMounts:
+        - name: data
+          mountPath: %s
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 1Gi
+`,
+		metadata,
+		engine.Port,
+		engine.Port,
+		inst.Type,
+		inst.Host,
+		metadata,
+		inst.Host,
+		inst.Host,
+		inst.Host,
+		inst.Type,
+		engine.Image,
+		command,
+		engine.Port,
+		inst.Type,
+		env.String(),
+		engine.HealthCheck,
+		engine.DataDir,
+	)
+}
+
+// GenerateDatabaseSecret generates the Secret with the credentials and the
+// connection string of a provisioned database. It holds plaintext passwords,
+// so it is written apart from the other manifests and must not be committed.
+func GenerateDatabaseSecret(inst database.Instance, config *GeneratorConfig, tsuboName string, labels map[string]string) string {
+	host := fmt.Sprintf("%s.%s.svc.cluster.local", inst.Host, config.Namespace)
+
+	var secretData strings.Builder
+	secretData.WriteString(fmt.Sprintf("  url: %q\n", inst.URL(host)))
+	for _, v := range inst.ContainerEnv() {
+		secretData.WriteString(fmt.Sprintf("  %s: %q\n", v.Name, v.Value))
+	}
+
+	return fmt.Sprintf(`# Generated by potter deploy generate. Contains database passwords: DO NOT COMMIT.
+apiVersion: v1
+kind: Secret
+%stype: Opaque
+stringData:
+%s`,
+		databaseMetadata(inst, config, tsuboName, labels),
+		secretData.String(),
+	)
+}
+
+// databaseMetadata is the metadata shared by the manifests of a database
+func databaseMetadata(inst database.Instance, config *GeneratorConfig, tsuboName string, labels map[string]string) string {
+	return fmt.Sprintf(`metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app: %s
+    app.kubernetes.io/name: %s
+    app.kubernetes.io/instance: %s
+    app.kubernetes.io/part-of: %s
+    app.kubernetes.io/component: database
+    app.kubernetes.io/managed-by: potter
+%s`,
+		inst.Host,
+		config.Namespace,
+		inst.Host,
+		inst.Host,
+		inst.Host,
+		tsuboName,
+		generateLabels(labels, "    "),
+	)
+}
@@ -4,15 +4,16 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/staka121/potter/pkg/database"
 	"github.com/staka121/potter/pkg/types"
 )
 
 // GenerateDeployment generates a Kubernetes Deployment manifest
-func GenerateDeployment(obj types.ObjectRef, config *GeneratorConfig, tsuboName string) string {
+func GenerateDeployment(obj types.ObjectRef, config *GeneratorConfig, tsuboName string, labels map[string]string) string {
 	imageName := getImageName(obj.Name, config.ImageRegistry, config.ImageTag)
 
-	// Generate environment variables for dependencies
-	envVars := generateEnvVars(obj.Dependencies, config.Namespace)
+	// Generate environment variables for dependencies and provisioned databases
+	envVars := generateEnvVars(obj.Dependencies, config.Databases[obj.Name], config.Namespace)
 
 	// Generate liveness/readiness probes from health_check
 	probes := generateProbes(obj.Runtime.HealthCheck, obj.Runtime.Port)
@@ -28,7 +29,7 @@ metadata:
     app.kubernetes.io/instance: %s
     app.kubernetes.io/part-of: %s
     app.kubernetes.io/managed-by: potter
-spec:
+%sspec:
   replicas: %d
   selector:
     matchLabels:
@@ -39,7 +40,7 @@ spec:
         app: %s
         app.kubernetes.io/name: %s
         app.kubernetes.io/instance: %s
-    spec:
+%s    spec:
       containers:
       - name: %s
         image: %s
@@ -62,11 +63,13 @@ spec:
 		obj.Name,
 		obj.Name,
 		tsuboName,
+		generateLabels(labels, "    "),
 		config.DefaultReplicas,
 		obj.Name,
 		obj.Name,
 		obj.Name,
 		obj.Name,
+		generateLabels(labels, "        "),
 		obj.Name,
 		imageName,
 		obj.Runtime.Port,
@@ -85,9 +88,10 @@ func getImageName(serviceName, registry, tag string) string {
 	return fmt.Sprintf("%s/%s:%s", registry, serviceName, tag)
 }
 
-// generateEnvVars generates environment variables for service dependencies
-func generateEnvVars(dependencies []string, namespace string) string {
-	if len(dependencies) == 0 {
+// generateEnvVars generates environment variables for service dependencies and
+// the connection strings of provisioned databases (read from their Secrets)
+func generateEnvVars(dependencies []string, databases []database.Instance, namespace string) string {
+	if len(dependencies) == 0 && len(databases) == 0 {
 		return ""
 	}
 
@@ -96,17 +100,32 @@ func generateEnvVars(dependencies []string, namespace string) string {
 
 	for _, dep := range dependencies {
 		// Generate environment variable for each dependency
-		// Format: SERVICE_NAME_URL=http://service-name.namespace.svc.cluster.local:port
-		envVarName := strings.ToUpper(strings.ReplaceAll(dep, "-", "_")) + "_URL"
-		serviceURL := fmt.Sprintf("http://%s.%s.svc.cluster.local", dep, namespace)
+		envVars.WriteString(fmt.Sprintf("        - name: %s\n", ServiceEnvVar(dep)))
+		envVars.WriteString(fmt.Sprintf("          value: \"%s\"\n", ServiceURL(dep, namespace)))
+	}
 
-		envVars.WriteString(fmt.Sprintf("        - name: %s\n", envVarName))
-		envVars.WriteString(fmt.Sprintf("          value: \"%s\"\n", serviceURL))
+	for _, db := range databases {
+		envVars.WriteString(fmt.Sprintf("        - name: %s\n", db.EnvVar()))
+		envVars.WriteString("          valueFrom:\n")
+		envVars.WriteString("            secretKeyRef:\n")
+		envVars.WriteString(fmt.Sprintf("              name: %s\n", db.Host))
+		envVars.WriteString("              key: url\n")
 	}
 
 	return envVars.String()
 }
 
+// ServiceEnvVar is the environment variable through which dependents reach a
+// service, e.g. USER_SERVICE_URL
+func ServiceEnvVar(service string) string {
+	return strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_URL"
+}
+
+// ServiceURL is the in-cluster URL of a service: http://service-name.namespace.svc.cluster.local
+func ServiceURL(service, namespace string) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local", service, namespace)
+}
+
 // generateProbes generates liveness and readiness probes
 func generateProbes(healthCheckPath string, port int) string {
 	if healthCheckPath == "" {
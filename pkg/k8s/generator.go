@@ -26,34 +26,45 @@ func NewGenerator(config *GeneratorConfig) *Generator {
 // Generate generates all Kubernetes manifests from a Tsubo definition
 func (g *Generator) Generate(tsuboDef *types.TsuboDefinition) (*ManifestSet, error) {
 	manifests := &ManifestSet{
-		Namespace:   g.config.Namespace,
-		Deployments: make([]string, 0),
-		Services:    make([]string, 0),
-		ConfigMaps:  make([]string, 0),
+		Namespace:       g.config.Namespace,
+		Deployments:     make([]string, 0),
+		Services:        make([]string, 0),
+		ConfigMaps:      make([]string, 0),
+		Databases:       make(map[string]string),
+		DatabaseSecrets: make(map[string]string),
 	}
 
 	tsuboName := tsuboDef.Tsubo.Name
+	labels := MetadataLabels(tsuboDef.Metadata)
 
 	// Generate namespace
-	namespaceManifest := GenerateNamespace(g.config.Namespace, tsuboName)
+	namespaceManifest := GenerateNamespace(g.config.Namespace, tsuboName, labels)
 
 	// Generate manifests for each object (service)
 	for _, obj := range tsuboDef.Objects {
-		// Skip gateway-service if Ingress is enabled (gateway is replaced by Ingress)
-		if obj.Name == "gateway-service" && g.config.Ingress != nil && g.config.Ingress.Enabled {
+		// Skip the gateway if Ingress is enabled (gateway is replaced by Ingress)
+		if obj.Name == tsuboDef.Gateway.Name && g.config.Ingress != nil && g.config.Ingress.Enabled {
 			continue
 		}
 
 		// Generate Deployment
-		deployment := GenerateDeployment(obj, g.config, tsuboName)
+		deployment := GenerateDeployment(obj, g.config, tsuboName, labels)
 		manifests.Deployments = append(manifests.Deployments, deployment)
 
 		// Generate Service
-		service := GenerateService(obj, g.config, tsuboName)
+		service := GenerateService(obj, g.config, tsuboName, labels)
 		manifests.Services = append(manifests.Services, service)
+
+		// Generate the databases of the object unless they are provided externally
+		if !g.config.ExternalDatabases {
+			for _, inst := range g.config.Databases[obj.Name] {
+				manifests.Databases[inst.Host] = GenerateDatabase(inst, g.config, tsuboName, labels)
+				manifests.DatabaseSecrets[inst.Host] = GenerateDatabaseSecret(inst, g.config, tsuboName, labels)
+			}
+		}
 	}
 
-	// Generate Ingress if enabled (replaces gateway-service)
+	// Generate Ingress if enabled (replaces the gateway)
 	if g.config.Ingress != nil && g.config.Ingress.Enabled {
 		ingress := GenerateIngress(tsuboDef, g.config, g.config.Ingress)
 		manifests.Ingress = ingress
@@ -99,6 +110,33 @@ func (g *Generator) writeManifests(namespaceManifest string, manifests *Manifest
 		}
 	}
 
+	// Write database manifests, named after the instance so that adding or
+	// reordering databases keeps the file names stable
+	for host, db := range manifests.Databases {
+		path := filepath.Join(outputDir, fmt.Sprintf("database-%s.yaml", host))
+		if err := os.WriteFile(path, []byte(db), 0644); err != nil {
+			return fmt.Errorf("failed to write database manifest: %w", err)
+		}
+	}
+
+	// Write database Secrets apart: they contain passwords
+	if len(manifests.DatabaseSecrets) > 0 {
+		secretsDir := filepath.Join(outputDir, SecretsDir)
+		if err := os.MkdirAll(secretsDir, 0700); err != nil {
+			return fmt.Errorf("failed to create secrets directory: %w", err)
+		}
+		gitignore := "# Generated Secrets contain passwords: never commit this directory\n*\n"
+		if err := os.WriteFile(filepath.Join(secretsDir, ".gitignore"), []byte(gitignore), 0644); err != nil {
+			return fmt.Errorf("failed to write secrets .gitignore: %w", err)
+		}
+		for host, secret := range manifests.DatabaseSecrets {
+			path := filepath.Join(secretsDir, fmt.Sprintf("database-%s.secret.yaml", host))
+			if err := os.WriteFile(path, []byte(secret), 0600); err != nil {
+				return fmt.Errorf("failed to write database secret: %w", err)
+			}
+		}
+	}
+
 	// Write ingress manifest if generated
 	if manifests.Ingress != "" {
 		ingressPath := filepath.Join(outputDir, "ingress.yaml")
@@ -111,9 +149,13 @@ func (g *Generator) writeManifests(namespaceManifest string, manifests *Manifest
 	fmt.Printf("   - Namespace: %s\n", namespacePath)
 	fmt.Printf("   - Deployments: %d\n", len(manifests.Deployments))
 	fmt.Printf("   - Services: %d\n", len(manifests.Services))
+	if len(manifests.Databases) > 0 {
+		fmt.Printf("   - Databases: %d (StatefulSet + PVC)\n", len(manifests.Databases))
+		fmt.Printf("   - Database Secrets: %s (git-ignored, do not commit)\n", filepath.Join(outputDir, SecretsDir))
+	}
 	if manifests.Ingress != "" {
 		ingressPath := filepath.Join(outputDir, "ingress.yaml")
-		fmt.Printf("   - Ingress: %s (replaces gateway-service)\n", ingressPath)
+		fmt.Printf("   - Ingress: %s (replaces the gateway)\n", ingressPath)
 	}
 
 	return nil
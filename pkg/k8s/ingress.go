@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/staka121/potter/internal/parser"
 	"github.com/staka121/potter/pkg/types"
 )
 
@@ -15,6 +16,7 @@ type IngressConfig struct {
 	TLSSecretName string
 	IngressClass string
 	Annotations map[string]string
+	Routes      []types.GatewayRoute // Gateway routes (nil = every object on its inferred prefix)
 }
 
 // DefaultIngressConfig returns default Ingress configuration
@@ -31,7 +33,7 @@ func DefaultIngressConfig() *IngressConfig {
 }
 
 // GenerateIngress generates a Kubernetes Ingress manifest
-// This replaces the gateway-service functionality with K8s native Ingress
+// This replaces the gateway's routing with K8s native Ingress
 func GenerateIngress(tsuboDef *types.TsuboDefinition, config *GeneratorConfig, ingressConfig *IngressConfig) string {
 	if !ingressConfig.Enabled {
 		return ""
@@ -75,7 +77,7 @@ metadata:
     app.kubernetes.io/part-of: %s
     app.kubernetes.io/managed-by: potter
     app.kubernetes.io/component: gateway
-  annotations:
+%s  annotations:
 %s
 spec:
 %s  rules:
@@ -85,6 +87,7 @@ spec:
 		tsuboName,
 		tsuboName,
 		tsuboName,
+		generateLabels(MetadataLabels(tsuboDef.Metadata), "    "),
 		annotations,
 		tlsConfig,
 		hostConfig,
@@ -98,54 +101,43 @@ spec:
 func generateIngressRules(tsuboDef *types.TsuboDefinition, namespace string, ingressConfig *IngressConfig) string {
 	var paths []string
 
-	// Generate routes based on gateway-service pattern
-	// Each service gets routed based on its base path
-	for _, obj := range tsuboDef.Objects {
-		// Skip gateway-service itself if it exists
-		if obj.Name == "gateway-service" {
-			continue
+	routes := ingressConfig.Routes
+	if routes == nil {
+		for _, obj := range tsuboDef.Objects {
+			routes = append(routes, types.GatewayRoute{
+				Service: obj.Name,
+				Paths:   []string{parser.InferPathPrefix(obj.Name)},
+			})
 		}
+	}
 
-		// Infer the path prefix from service name
-		// user-service -> /api/v1/users
-		// todo-service -> /api/v1/todos
-		pathPrefix := inferPathPrefix(obj.Name)
+	// Generate routes the same way the gateway routes them:
+	// each path prefix goes to its service, internal-only services are not exposed
+	for _, route := range routes {
+		// Skip the gateway itself if it is declared as an object
+		if route.Service == tsuboDef.Gateway.Name || route.Exclude {
+			continue
+		}
 
-		path := fmt.Sprintf(`        - path: %s(/|$)(.*)
+		for _, pathPrefix := range route.Paths {
+			path := fmt.Sprintf(`        - path: %s(/|$)(.*)
           pathType: ImplementationSpecific
           backend:
             service:
               name: %s
               port:
                 number: 80`,
-			pathPrefix,
-			obj.Name,
-		)
-		paths = append(paths, path)
+				pathPrefix,
+				route.Service,
+			)
+			paths = append(paths, path)
+		}
 	}
 
 	// Combine all paths under a single "paths:" key
 	return "        paths:\n" + strings.Join(paths, "\n")
 }
 
-// inferPathPrefix infers the API path prefix from service name
-// Examples:
-//   user-service -> /api/v1/users
-//   todo-service -> /api/v1/todos
-//   product-service -> /api/v1/products
-func inferPathPrefix(serviceName string) string {
-	// Remove "-service" suffix if present
-	name := strings.TrimSuffix(serviceName, "-service")
-
-	// Pluralize the name (simple heuristic)
-	plural := name
-	if !strings.HasSuffix(name, "s") {
-		plural = name + "s"
-	}
-
-	return fmt.Sprintf("/api/v1/%s", plural)
-}
-
 // generateAnnotations generates Ingress annotations
 func generateAnnotations(customAnnotations map[string]string, ingressClass string) string {
 	annotations := make(map[string]string)
@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// MetadataLabels returns the labels derived from the tsubo metadata section.
+// metadata.version becomes app.kubernetes.io/version and metadata.labels are
+// added as-is.
+func MetadataLabels(metadata types.Metadata) map[string]string {
+	labels := make(map[string]string, len(metadata.Labels)+1)
+	if metadata.Version != "" {
+		labels["app.kubernetes.io/version"] = metadata.Version
+	}
+	for k, v := range metadata.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// generateLabels renders labels as YAML lines with the given indentation, sorted by key
+func generateLabels(labels map[string]string, indent string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s%s: %q\n", indent, k, labels[k]))
+	}
+	return sb.String()
+}
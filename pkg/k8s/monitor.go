@@ -139,7 +139,7 @@ func generatePrometheusRule(name string, latency types.LatencyConfig, config *Mo
 	sb.WriteString("apiVersion: monitoring.coreos.com/v1\n")
 	sb.WriteString("kind: PrometheusRule\n")
 	sb.WriteString("metadata:\n")
-	sb.WriteString(fmt.Sprintf("  name: %s-sla\n", name))
+	sb.WriteString(fmt.Sprintf("  name: %s\n", PrometheusRuleName(name)))
 	sb.WriteString(fmt.Sprintf("  namespace: %s\n", namespace))
 	sb.WriteString("  labels:\n")
 	sb.WriteString(fmt.Sprintf("    app: %s\n", name))
@@ -150,7 +150,7 @@ func generatePrometheusRule(name string, latency types.LatencyConfig, config *Mo
 	sb.WriteString("      rules:\n")
 
 	for _, r := range rules {
-		alertName := fmt.Sprintf("SLAViolation%s", strings.ToUpper(r.label))
+		alertName := slaAlertName(r.label)
 		expr := fmt.Sprintf(
 			`histogram_quantile(%.2f, rate(http_request_duration_seconds_bucket{app="%s"}[5m])) > %s`,
 			r.quantile, name, formatSeconds(r.threshold),
@@ -169,6 +169,30 @@ func generatePrometheusRule(name string, latency types.LatencyConfig, config *Mo
 	return sb.String(), nil
 }
 
+// PrometheusRuleName is the name of the PrometheusRule holding a service's SLA alerts
+func PrometheusRuleName(service string) string {
+	return service + "-sla"
+}
+
+// SLAAlerts returns the names of the alerts generated for the latency SLAs
+func SLAAlerts(latency types.LatencyConfig) []string {
+	var alerts []string
+	for _, entry := range []struct{ raw, label string }{
+		{latency.P50, "p50"},
+		{latency.P95, "p95"},
+		{latency.P99, "p99"},
+	} {
+		if entry.raw != "" {
+			alerts = append(alerts, slaAlertName(entry.label))
+		}
+	}
+	return alerts
+}
+
+func slaAlertName(label string) string {
+	return fmt.Sprintf("SLAViolation%s", strings.ToUpper(label))
+}
+
 // parseDurationToSeconds parses a duration string like "50ms", "1s", "200ms" into seconds.
 func parseDurationToSeconds(s string) (float64, error) {
 	s = strings.TrimSpace(s)
@@ -5,7 +5,7 @@ import (
 )
 
 // GenerateNamespace generates a Kubernetes Namespace manifest
-func GenerateNamespace(namespace, tsuboName string) string {
+func GenerateNamespace(namespace, tsuboName string, labels map[string]string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: Namespace
 metadata:
@@ -13,5 +13,5 @@ metadata:
   labels:
     app.kubernetes.io/name: %s
     app.kubernetes.io/managed-by: potter
-`, namespace, tsuboName)
+%s`, namespace, tsuboName, generateLabels(labels, "    "))
 }
@@ -7,7 +7,7 @@ import (
 )
 
 // GenerateService generates a Kubernetes Service manifest
-func GenerateService(obj types.ObjectRef, config *GeneratorConfig, tsuboName string) string {
+func GenerateService(obj types.ObjectRef, config *GeneratorConfig, tsuboName string, labels map[string]string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: Service
 metadata:
@@ -19,7 +19,7 @@ metadata:
     app.kubernetes.io/instance: %s
     app.kubernetes.io/part-of: %s
     app.kubernetes.io/managed-by: potter
-spec:
+%sspec:
   type: ClusterIP
   ports:
   - port: 80
@@ -35,6 +35,7 @@ spec:
 		obj.Name,
 		obj.Name,
 		tsuboName,
+		generateLabels(labels, "    "),
 		obj.Runtime.Port,
 		obj.Name,
 	)
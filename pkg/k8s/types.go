@@ -1,5 +1,7 @@
 package k8s
 
+import "github.com/staka121/potter/pkg/database"
+
 // GeneratorConfig contains configuration for K8s manifest generation
 type GeneratorConfig struct {
 	Namespace       string
@@ -8,6 +10,12 @@ type GeneratorConfig struct {
 	ImageTag        string
 	DefaultReplicas int32
 	Ingress         *IngressConfig
+
+	// Databases provisioned for each object (object name -> instances with credentials)
+	Databases map[string][]database.Instance
+	// ExternalDatabases references existing Secrets (<service>-<database>, key "url")
+	// instead of generating a StatefulSet per database
+	ExternalDatabases bool
 }
 
 // DefaultGeneratorConfig returns default configuration
@@ -29,4 +37,13 @@ type ManifestSet struct {
 	Services    []string
 	ConfigMaps  []string
 	Ingress     string
+	Databases   map[string]string // Service and StatefulSet of each provisioned database, by host
+
+	// DatabaseSecrets holds the Secret of each provisioned database, by host.
+	// They contain plaintext passwords and are written to SecretsDir.
+	DatabaseSecrets map[string]string
 }
+
+// SecretsDir is the subdirectory of the output directory that receives
+// generated Secrets. It ignores itself in git.
+const SecretsDir = "secrets"
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/staka121/potter/internal/executor"
+	"github.com/staka121/potter/internal/planner"
 	"github.com/staka121/potter/pkg/types"
 )
 
@@ -15,18 +16,18 @@ func ExecuteMigration(
 	tsubo *types.TsuboDefinition,
 	tsuboFile string,
 	state *types.PotterState,
-	apiKey string,
+	client executor.Implementer,
+	config *types.ProjectConfig,
 	concurrency int,
 ) error {
 	contractsDir := filepath.Dir(tsuboFile)
-	tsuboDir := filepath.Dir(tsuboFile)
-	implementationsDir := filepath.Join(tsuboDir, "implementations")
+	implementationsDir := config.ImplementationsDir
 
 	for _, step := range plan.Steps {
 		switch step.Action {
 		case "implement_new", "reimplement":
 			fmt.Printf("\n  🔨 [%s] %s\n", step.Action, step.ServiceName)
-			if err := executeServiceBuild(step.ServiceName, tsubo, tsuboFile, contractsDir, implementationsDir, concurrency); err != nil {
+			if err := executeServiceBuild(step.ServiceName, tsubo, tsuboFile, contractsDir, client, config, concurrency); err != nil {
 				return fmt.Errorf("failed to %s %s: %w", step.Action, step.ServiceName, err)
 			}
 
@@ -54,7 +55,8 @@ func executeServiceBuild(
 	tsubo *types.TsuboDefinition,
 	tsuboFile string,
 	contractsDir string,
-	implementationsDir string,
+	client executor.Implementer,
+	config *types.ProjectConfig,
 	concurrency int,
 ) error {
 	// Find the object definition
@@ -71,9 +73,9 @@ func executeServiceBuild(
 	}
 
 	// Build a minimal implementation plan for this single service
-	plan := buildSingleServicePlan(tsubo, tsuboFile, contractsDir, implementationsDir, *targetObj)
+	plan := buildSingleServicePlan(tsubo, tsuboFile, contractsDir, config, *targetObj)
 
-	runner, err := executor.NewRunner(plan)
+	runner, err := executor.NewRunner(plan, client)
 	if err != nil {
 		return fmt.Errorf("failed to create runner: %w", err)
 	}
@@ -81,6 +83,7 @@ func executeServiceBuild(
 	if concurrency > 0 {
 		runner.SetConcurrency(concurrency)
 	}
+	runner.SetRepair(executor.NewRepairPolicy(config))
 
 	result, err := runner.ExecuteSingle(serviceName)
 	if err != nil {
@@ -99,11 +102,9 @@ func buildSingleServicePlan(
 	tsubo *types.TsuboDefinition,
 	tsuboFile string,
 	contractsDir string,
-	implementationsDir string,
+	config *types.ProjectConfig,
 	obj types.ObjectRef,
 ) *types.ImplementationPlan {
-	projectRoot := filepath.Join(contractsDir, "..", "..")
-
 	wave := types.Wave{
 		Wave:     0,
 		Parallel: false,
@@ -122,31 +123,15 @@ func buildSingleServicePlan(
 		Tsubo:              tsubo.Tsubo.Name,
 		TsuboFile:          tsuboFile,
 		ContractsDir:       contractsDir,
-		ProjectRoot:        projectRoot,
-		ImplementationsDir: implementationsDir,
-		ContextFiles:       getExistingContextFiles(projectRoot),
+		ProjectRoot:        config.ProjectRoot,
+		ImplementationsDir: config.ImplementationsDir,
+		ArtifactsDir:       config.ArtifactsDir,
+		ContextFiles:       planner.GetContextFiles(config.ContextFiles),
+		Network:            tsubo.Deployment.Network,
 		Waves:              []types.Wave{wave},
 	}
 }
 
-// getExistingContextFiles returns context doc files that exist on disk
-func getExistingContextFiles(projectRoot string) []string {
-	candidates := []string{
-		filepath.Join(projectRoot, "docs", "PHILOSOPHY.md"),
-		filepath.Join(projectRoot, "docs", "DEVELOPMENT_PRINCIPLES.md"),
-		filepath.Join(projectRoot, "docs", "WHY_GO.md"),
-		filepath.Join(projectRoot, "docs", "CONTRACT_DESIGN.md"),
-	}
-
-	var existing []string
-	for _, f := range candidates {
-		if _, err := os.Stat(f); err == nil {
-			existing = append(existing, f)
-		}
-	}
-	return existing
-}
-
 // removeServiceImpl deletes the implementation directory for a service
 func removeServiceImpl(implementationsDir, serviceName string) error {
 	serviceDir := filepath.Join(implementationsDir, serviceName)
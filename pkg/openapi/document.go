@@ -0,0 +1,89 @@
+package openapi
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.1.0"
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string              `yaml:"openapi" json:"openapi"`
+	Info       Info                `yaml:"info" json:"info"`
+	Servers    []Server            `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Tags       []Tag               `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Paths      map[string]PathItem `yaml:"paths" json:"paths"`
+	Components Components          `yaml:"components,omitempty" json:"components,omitempty"`
+}
+
+// Info contains the document metadata
+type Info struct {
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Version     string `yaml:"version" json:"version"`
+}
+
+// Server describes where the API is served
+type Server struct {
+	URL         string `yaml:"url" json:"url"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Tag groups operations (one tag per service)
+type Tag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to operations
+type PathItem map[string]*Operation
+
+// Operation describes a single endpoint
+type Operation struct {
+	OperationID string               `yaml:"operationId" json:"operationId"`
+	Summary     string               `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Description string               `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags        []string             `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Parameters  []Parameter          `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty" json:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses" json:"responses"`
+	Servers     []Server             `yaml:"servers,omitempty" json:"servers,omitempty"` // Overrides the document's servers
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string       `yaml:"name" json:"name"`
+	In          string       `yaml:"in" json:"in"` // "path" | "query" | "header"
+	Description string       `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool         `yaml:"required,omitempty" json:"required,omitempty"`
+	Schema      SchemaObject `yaml:"schema" json:"schema"`
+}
+
+// RequestBody describes the request payload
+type RequestBody struct {
+	Required bool                 `yaml:"required,omitempty" json:"required,omitempty"`
+	Content  map[string]MediaType `yaml:"content" json:"content"`
+}
+
+// Response describes a single response
+type Response struct {
+	Description string               `yaml:"description" json:"description"`
+	Content     map[string]MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+// MediaType holds the schema and examples of a payload
+type MediaType struct {
+	Schema   SchemaObject       `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Examples map[string]Example `yaml:"examples,omitempty" json:"examples,omitempty"`
+}
+
+// Example is a named example value
+type Example struct {
+	Summary string      `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Value   interface{} `yaml:"value" json:"value"`
+}
+
+// Components holds reusable schemas
+type Components struct {
+	Schemas map[string]SchemaObject `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+}
+
+// SchemaObject is a JSON Schema (2020-12) as used by OpenAPI 3.1
+type SchemaObject map[string]interface{}
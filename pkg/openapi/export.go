@@ -0,0 +1,603 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/staka121/potter/pkg/schema"
+	"github.com/staka121/potter/pkg/types"
+)
+
+// componentsPrefix is the prefix of references to reusable schemas
+const componentsPrefix = "#/components/schemas/"
+
+// nonSlugChars matches characters not allowed in example keys
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FromObject converts an object contract into an OpenAPI document.
+// serverURL is the address the service is reachable at (empty to omit servers).
+func FromObject(obj *types.ObjectDefinition, serverURL string) (*Document, error) {
+	c := newConverter(obj, nil)
+
+	schemas, err := c.components()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := c.paths()
+	if err != nil {
+		return nil, err
+	}
+
+	version := obj.API.Version
+	if version == "" {
+		version = obj.Version
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       obj.Service.Name,
+			Description: strings.TrimSpace(obj.Service.Description),
+			Version:     version,
+		},
+		Tags:       []Tag{{Name: obj.Service.Name, Description: strings.TrimSpace(obj.Service.Description)}},
+		Paths:      paths,
+		Components: Components{Schemas: schemas},
+	}
+	if serverURL != "" {
+		doc.Servers = []Server{{URL: serverURL, Description: obj.Service.Name}}
+	}
+	return doc, nil
+}
+
+// ServiceURL is the address an object of the tsubo is reachable at on its own port
+func ServiceURL(objRef types.ObjectRef) string {
+	return fmt.Sprintf("http://%s:%d", objRef.Name, objRef.Runtime.Port)
+}
+
+// FromTsubo builds a single document for the whole tsubo as seen through the gateway.
+// objects maps object names to their parsed contracts. Schemas with the same name
+// but different definitions in several services are prefixed with the service name.
+// Without a gatewayURL (the gateway is disabled), each operation is served by its
+// own service at ServiceURL.
+func FromTsubo(tsubo *types.TsuboDefinition, objects map[string]*types.ObjectDefinition, gatewayURL string) (*Document, error) {
+	// First pass: find component names defined differently by several services
+	definitions := make(map[string][]SchemaObject)
+	for _, objRef := range tsubo.Objects {
+		obj, ok := objects[objRef.Name]
+		if !ok {
+			continue
+		}
+		schemas, err := newConverter(obj, nil).components()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", objRef.Name, err)
+		}
+		for name, s := range schemas {
+			definitions[name] = append(definitions[name], s)
+		}
+	}
+
+	conflicts := make(map[string]bool)
+	for name, defs := range definitions {
+		for _, d := range defs[1:] {
+			if !reflect.DeepEqual(defs[0], d) {
+				conflicts[name] = true
+				break
+			}
+		}
+	}
+
+	version := tsubo.Metadata.Version
+	if version == "" {
+		version = tsubo.Version
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       tsubo.Tsubo.Name,
+			Description: strings.TrimSpace(tsubo.Tsubo.Description),
+			Version:     version,
+		},
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]SchemaObject)},
+	}
+	if gatewayURL != "" {
+		doc.Servers = []Server{{URL: gatewayURL, Description: "API Gateway"}}
+	}
+
+	// Second pass: convert with conflicting names prefixed
+	operationIDs := make(map[string]bool)
+	for _, objRef := range tsubo.Objects {
+		obj, ok := objects[objRef.Name]
+		if !ok {
+			continue
+		}
+
+		rename := make(map[string]string)
+		for name := range conflicts {
+			rename[name] = objRef.Name + "." + name
+		}
+		c := newConverter(obj, rename)
+
+		schemas, err := c.components()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", objRef.Name, err)
+		}
+		for name, s := range schemas {
+			doc.Components.Schemas[name] = s
+		}
+
+		paths, err := c.paths()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", objRef.Name, err)
+		}
+		for _, path := range sortedKeys(paths) {
+			if _, exists := doc.Paths[path]; !exists {
+				doc.Paths[path] = make(PathItem)
+			}
+			for _, method := range sortedKeys(paths[path]) {
+				op := paths[path][method]
+				if _, exists := doc.Paths[path][method]; exists {
+					return nil, fmt.Errorf("%s: route %s %s is already defined by another service", objRef.Name, strings.ToUpper(method), path)
+				}
+				// operationId must be unique across the whole document
+				if operationIDs[op.OperationID] {
+					op.OperationID = objRef.Name + "." + op.OperationID
+				}
+				operationIDs[op.OperationID] = true
+				if gatewayURL == "" {
+					op.Servers = []Server{{URL: ServiceURL(objRef), Description: obj.Service.Name}}
+				}
+				doc.Paths[path][method] = op
+			}
+		}
+
+		doc.Tags = append(doc.Tags, Tag{Name: obj.Service.Name, Description: strings.TrimSpace(obj.Service.Description)})
+	}
+
+	return doc, nil
+}
+
+// converter converts a single object contract
+type converter struct {
+	obj   *types.ObjectDefinition
+	names map[string]string // Qualified type name ("Name" or "file#Name") -> component name
+}
+
+// newConverter assigns component names to local and imported types.
+// Imported types keep their name unless it is already taken, in which case
+// they are prefixed with the library name. rename overrides final names.
+func newConverter(obj *types.ObjectDefinition, rename map[string]string) *converter {
+	c := &converter{obj: obj, names: make(map[string]string)}
+	taken := make(map[string]bool)
+
+	for _, name := range sortedKeys(obj.Types) {
+		c.names[name] = name
+		taken[name] = true
+	}
+	for _, file := range sortedKeys(obj.ImportedTypes) {
+		for _, name := range sortedKeys(obj.ImportedTypes[file]) {
+			component := name
+			if taken[component] {
+				component = libraryName(file) + "." + name
+			}
+			c.names[file+"#"+name] = component
+			taken[component] = true
+		}
+	}
+
+	for key, component := range c.names {
+		if renamed, ok := rename[component]; ok {
+			c.names[key] = renamed
+		}
+	}
+	return c
+}
+
+// libraryName derives a short name from a library path ("common.types.yaml" -> "common")
+func libraryName(file string) string {
+	base := filepath.Base(file)
+	for _, suffix := range []string{".types.yaml", ".types.yml", ".yaml", ".yml"} {
+		if strings.HasSuffix(base, suffix) {
+			return strings.TrimSuffix(base, suffix)
+		}
+	}
+	return base
+}
+
+// components converts local and imported types into reusable schemas
+func (c *converter) components() (map[string]SchemaObject, error) {
+	schemas := make(map[string]SchemaObject)
+
+	add := func(file, name string, def types.TypeDef) error {
+		s, err := schema.ParseType(def)
+		if err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		obj, err := c.schemaObject(s, file)
+		if err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		key := name
+		if file != "" {
+			key = file + "#" + name
+		}
+		schemas[c.names[key]] = obj
+		return nil
+	}
+
+	for _, name := range sortedKeys(c.obj.Types) {
+		if err := add("", name, c.obj.Types[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range sortedKeys(c.obj.ImportedTypes) {
+		for _, name := range sortedKeys(c.obj.ImportedTypes[file]) {
+			if err := add(file, name, c.obj.ImportedTypes[file][name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return schemas, nil
+}
+
+// paths converts every endpoint into an operation.
+// Paths include the contract's base_path.
+func (c *converter) paths() (map[string]PathItem, error) {
+	paths := make(map[string]PathItem)
+	for _, ep := range c.obj.API.Endpoints {
+		op, err := c.operation(ep)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", ep.ID, err)
+		}
+		path := c.obj.API.BasePath + ep.Path
+		if _, exists := paths[path]; !exists {
+			paths[path] = make(PathItem)
+		}
+		paths[path][strings.ToLower(ep.Method)] = op
+	}
+	return paths, nil
+}
+
+// operation converts a single endpoint
+func (c *converter) operation(ep types.Endpoint) (*Operation, error) {
+	op := &Operation{
+		OperationID: ep.ID,
+		Summary:     strings.TrimSpace(ep.Description),
+		Description: operationDescription(ep.Semantics),
+		Tags:        []string{c.obj.Service.Name},
+		Responses:   make(map[string]*Response),
+	}
+
+	for _, loc := range []struct{ key, in string }{
+		{"path_params", "path"},
+		{"query_params", "query"},
+		{"headers", "header"},
+	} {
+		params, err := c.parameters(ep.Request[loc.key], loc.in)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", loc.key, err)
+		}
+		op.Parameters = append(op.Parameters, params...)
+	}
+
+	if raw, ok := ep.Request["schema"]; ok {
+		s, err := c.resolveRaw(raw)
+		if err != nil {
+			return nil, fmt.Errorf("request: %w", err)
+		}
+		contentType, _ := ep.Request["content_type"].(string)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentType: {Schema: s}},
+		}
+	}
+
+	for _, code := range sortedKeys(ep.Response) {
+		resp, err := c.response(code, ep.Response[code])
+		if err != nil {
+			return nil, fmt.Errorf("response %s: %w", code, err)
+		}
+		op.Responses[code] = resp
+	}
+
+	c.addExamples(op, ep.Semantics)
+
+	return op, nil
+}
+
+// parameters converts path_params, query_params or headers into parameters
+func (c *converter) parameters(raw interface{}, in string) ([]Parameter, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var params []Parameter
+	for _, name := range sortedKeys(m) {
+		s, err := schema.Parse(m[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		obj, err := c.schemaObject(s, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		delete(obj, "description") // Described on the parameter itself
+
+		required := in == "path"
+		if propMap, ok := m[name].(map[string]interface{}); ok {
+			if r, ok := propMap["required"].(bool); ok && r {
+				required = true
+			}
+		}
+
+		params = append(params, Parameter{
+			Name:        name,
+			In:          in,
+			Description: s.Description,
+			Required:    required,
+			Schema:      obj,
+		})
+	}
+	return params, nil
+}
+
+// response converts a single response entry
+func (c *converter) response(code string, raw interface{}) (*Response, error) {
+	resp := &Response{Description: defaultDescription(code)}
+
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return resp, nil
+	}
+	if desc, ok := m["description"].(string); ok && strings.TrimSpace(desc) != "" {
+		resp.Description = strings.TrimSpace(desc)
+	}
+	if rawSchema, ok := m["schema"]; ok {
+		s, err := c.resolveRaw(rawSchema)
+		if err != nil {
+			return nil, err
+		}
+		resp.Content = map[string]MediaType{"application/json": {Schema: s}}
+	}
+	return resp, nil
+}
+
+// addExamples attaches semantics examples and edge case bodies as OpenAPI examples
+func (c *converter) addExamples(op *Operation, sem types.Semantics) {
+	for _, ex := range sem.Examples {
+		key := slug(ex.Name)
+
+		if body, ok := exampleRequestBody(ex.Request); ok && op.RequestBody != nil {
+			for contentType, media := range op.RequestBody.Content {
+				op.RequestBody.Content[contentType] = withExample(media, key, ex.Name, body)
+			}
+		}
+
+		if ex.Response.Status != 0 && ex.Response.Body != nil {
+			c.addResponseExample(op, strconv.Itoa(ex.Response.Status), "", key, ex.Name, ex.Response.Body)
+		}
+	}
+
+	for _, ec := range sem.Behavior.EdgeCases {
+		if ec.Status == 0 || ec.Body == nil {
+			continue
+		}
+		description := ec.Reason
+		if description == "" {
+			description = ec.Response
+		}
+		c.addResponseExample(op, strconv.Itoa(ec.Status), description, slug(ec.Case), ec.Case, ec.Body)
+	}
+}
+
+// requestParts are the keys of an example request that do not belong to its body
+var requestParts = map[string]bool{"body": true, "path": true, "query": true, "headers": true}
+
+// exampleRequestBody returns the body of an example request: its body key, or
+// the request itself in the flat form, where the body fields are written
+// directly under request (path, query and headers are not part of the body)
+func exampleRequestBody(request map[string]interface{}) (interface{}, bool) {
+	if body, ok := request["body"]; ok {
+		return body, true
+	}
+	body := make(map[string]interface{}, len(request))
+	for key, value := range request {
+		if !requestParts[key] {
+			body[key] = value
+		}
+	}
+	return body, len(body) > 0
+}
+
+// addResponseExample adds an example to a response, creating the response if needed
+func (c *converter) addResponseExample(op *Operation, code, description, key, summary string, value interface{}) {
+	resp, ok := op.Responses[code]
+	if !ok {
+		if description == "" {
+			description = defaultDescription(code)
+		}
+		resp = &Response{Description: description}
+		op.Responses[code] = resp
+	}
+	if resp.Content == nil {
+		resp.Content = map[string]MediaType{"application/json": {}}
+	}
+	for contentType, media := range resp.Content {
+		resp.Content[contentType] = withExample(media, key, summary, value)
+	}
+}
+
+func withExample(media MediaType, key, summary string, value interface{}) MediaType {
+	if media.Examples == nil {
+		media.Examples = make(map[string]Example)
+	}
+	unique := key
+	for i := 2; ; i++ {
+		if _, exists := media.Examples[unique]; !exists {
+			break
+		}
+		unique = fmt.Sprintf("%s_%d", key, i)
+	}
+	media.Examples[unique] = Example{Summary: summary, Value: value}
+	return media
+}
+
+// resolveRaw converts a raw contract schema
+func (c *converter) resolveRaw(raw interface{}) (SchemaObject, error) {
+	s, err := schema.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return c.schemaObject(s, "")
+}
+
+// schemaObject converts a schema into a JSON Schema object.
+// file is the library the schema belongs to ("" for the contract itself);
+// local references inside a library refer to that library.
+func (c *converter) schemaObject(s *schema.Schema, file string) (SchemaObject, error) {
+	out := SchemaObject{}
+
+	if s.Ref != "" {
+		refFile, name, err := schema.ParseRef(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if refFile == "" {
+			refFile = file
+		}
+		key := name
+		if refFile != "" {
+			key = refFile + "#" + name
+		}
+		component, ok := c.names[key]
+		if !ok {
+			return nil, fmt.Errorf("dangling $ref %q", s.Ref)
+		}
+
+		ref := SchemaObject{"$ref": componentsPrefix + component}
+		if s.Nullable {
+			out["anyOf"] = []interface{}{ref, SchemaObject{"type": "null"}}
+		} else {
+			out = ref
+		}
+		if s.Description != "" {
+			out["description"] = s.Description
+		}
+		return out, nil
+	}
+
+	if s.Type != "" {
+		if s.Nullable {
+			out["type"] = []interface{}{s.Type, "null"}
+		} else {
+			out["type"] = s.Type
+		}
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if len(s.Enum) > 0 {
+		enum := make([]interface{}, len(s.Enum))
+		for i, e := range s.Enum {
+			enum[i] = e
+		}
+		out["enum"] = enum
+	}
+	if s.MinLength != nil {
+		out["minLength"] = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		out["maxLength"] = *s.MaxLength
+	}
+	if s.Example != nil {
+		out["examples"] = []interface{}{s.Example}
+	}
+
+	if len(s.Properties) > 0 {
+		props := make(map[string]interface{}, len(s.Properties))
+		for _, name := range s.PropertyNames() {
+			prop, err := c.schemaObject(s.Properties[name], file)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+			props[name] = prop
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		required := make([]interface{}, len(s.Required))
+		for i, r := range s.Required {
+			required[i] = r
+		}
+		out["required"] = required
+	}
+
+	if s.Items != nil {
+		items, err := c.schemaObject(s.Items, file)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		out["items"] = items
+	}
+
+	return out, nil
+}
+
+// operationDescription combines the semantics intent and success behavior
+func operationDescription(sem types.Semantics) string {
+	var parts []string
+	if sem.Intent != "" {
+		parts = append(parts, sem.Intent)
+	}
+	if sem.Behavior.Success != "" {
+		parts = append(parts, "**Success:**\n\n"+sem.Behavior.Success)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// defaultDescription returns the standard reason phrase of a status code
+func defaultDescription(code string) string {
+	if n, err := strconv.Atoi(code); err == nil {
+		if text := http.StatusText(n); text != "" {
+			return text
+		}
+	}
+	if code == "default" {
+		return "Default response"
+	}
+	return "Response"
+}
+
+// slug converts a name into an example key
+func slug(name string) string {
+	s := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if s == "" {
+		return "example"
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
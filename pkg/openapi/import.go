@@ -0,0 +1,587 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TODO placeholders written into imported contracts
+const (
+	TodoPurpose          = "TODO: describe the purpose of this service"
+	TodoResponsibilities = "TODO: list the responsibilities of this service"
+	TodoIntent           = "TODO: describe the intent of this endpoint"
+)
+
+// httpMethods lists the operation keys of a path item in output order
+var httpMethods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// supportedTypeKeys lists the top-level keywords of a contract type definition
+var supportedTypeKeys = map[string]bool{
+	"description": true, "type": true, "properties": true, "required": true,
+	"enum": true, "items": true, "format": true,
+}
+
+// nonIDChars matches characters not allowed in generated endpoint IDs
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ImportResult is the outcome of converting an OpenAPI document into a contract
+type ImportResult struct {
+	Contract    []byte   // .object.yaml content
+	Description string   // Service description (from info)
+	Warnings    []string // Constructs that could not be represented
+}
+
+// importer converts an OpenAPI document into an object contract
+type importer struct {
+	spec     map[string]interface{}
+	warnings []string
+}
+
+// ToObject converts an OpenAPI 3.x document (YAML or JSON) into an .object.yaml contract.
+// The service context is left with TODO placeholders.
+func ToObject(data []byte, serviceName string) (*ImportResult, error) {
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	version, _ := spec["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported document: expected OpenAPI 3.x, got openapi %q", version)
+	}
+
+	im := &importer{spec: spec}
+
+	info := mapValue(spec["info"])
+	description := strings.TrimSpace(stringOf(info["description"]))
+	if description == "" {
+		description = stringOf(info["title"])
+	}
+
+	basePath := im.basePath()
+	endpoints, err := im.endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	service := mappingNode(
+		"name", scalarNode(serviceName),
+		"description", scalarNode(description),
+		"context", mappingNode(
+			"purpose", scalarNode(TodoPurpose),
+			"responsibilities", sequenceNode(scalarNode(TodoResponsibilities)),
+		),
+	)
+
+	api := mappingNode("version", scalarNode(stringOf(info["version"])))
+	if basePath != "" {
+		appendPair(api, "base_path", scalarNode(basePath))
+	}
+	appendPair(api, "endpoints", endpoints)
+
+	root := mappingNode(
+		"version", quotedNode("1.0"),
+		"service", service,
+		"api", api,
+	)
+	if typesNode := im.types(); len(typesNode.Content) > 0 {
+		appendPair(root, "types", typesNode)
+	}
+	appendPair(root, "dependencies", mappingNode("services", emptySequenceNode()))
+
+	title := stringOf(info["title"])
+	root.HeadComment = fmt.Sprintf("Tsubo Object: %s\nImported from OpenAPI: %s", serviceName, title)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode contract: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode contract: %w", err)
+	}
+
+	return &ImportResult{
+		Contract:    buf.Bytes(),
+		Description: description,
+		Warnings:    im.warnings,
+	}, nil
+}
+
+func (im *importer) warnf(format string, args ...interface{}) {
+	im.warnings = append(im.warnings, fmt.Sprintf(format, args...))
+}
+
+// basePath takes the path of the first server URL ("https://x/api/v1" -> "/api/v1")
+func (im *importer) basePath() string {
+	servers, _ := im.spec["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(stringOf(mapValue(servers[0])["url"]))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// types converts components.schemas into contract types
+func (im *importer) types() *yaml.Node {
+	out := mappingNode()
+	schemas := mapValue(mapValue(im.spec["components"])["schemas"])
+
+	for _, name := range sortedKeys(schemas) {
+		raw := im.schema(schemas[name])
+		def := mapValue(raw)
+		if def == nil {
+			im.warnf("type %s: not an object schema, skipped", name)
+			continue
+		}
+		for _, key := range sortedKeys(def) {
+			if !supportedTypeKeys[key] {
+				im.warnf("type %s: unsupported keyword %q dropped", name, key)
+				delete(def, key)
+			}
+		}
+		if enum, ok := def["enum"].([]interface{}); ok {
+			for i, v := range enum {
+				enum[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		appendPair(out, name, valueNode(def))
+	}
+	return out
+}
+
+// endpoints converts paths and operations into api.endpoints
+func (im *importer) endpoints() (*yaml.Node, error) {
+	out := sequenceNode()
+	paths := mapValue(im.spec["paths"])
+	ids := make(map[string]bool)
+
+	for _, path := range sortedKeys(paths) {
+		item := mapValue(im.deref(paths[path]))
+		shared, _ := item["parameters"].([]interface{})
+
+		for _, method := range httpMethods {
+			op := mapValue(item[method])
+			if op == nil {
+				continue
+			}
+
+			id := stringOf(op["operationId"])
+			if id == "" {
+				id = strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(method+"_"+path), "_"), "_")
+			}
+			if ids[id] {
+				return nil, fmt.Errorf("duplicate operationId %q", id)
+			}
+			ids[id] = true
+
+			ep, err := im.endpoint(id, method, path, op, shared)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			out.Content = append(out.Content, ep)
+		}
+	}
+
+	return out, nil
+}
+
+// endpoint converts a single operation
+func (im *importer) endpoint(id, method, path string, op map[string]interface{}, shared []interface{}) (*yaml.Node, error) {
+	ep := mappingNode(
+		"id", scalarNode(id),
+		"method", scalarNode(strings.ToUpper(method)),
+		"path", scalarNode(path),
+	)
+	if summary := strings.TrimSpace(stringOf(op["summary"])); summary != "" {
+		appendPair(ep, "description", scalarNode(summary))
+	}
+
+	request := mappingNode()
+	params := map[string]*yaml.Node{
+		"path":   mappingNode(),
+		"query":  mappingNode(),
+		"header": mappingNode(),
+	}
+	opParams, _ := op["parameters"].([]interface{})
+	for _, rawParam := range append(append([]interface{}{}, shared...), opParams...) {
+		param := mapValue(im.deref(rawParam))
+		in := stringOf(param["in"])
+		target, ok := params[in]
+		if !ok {
+			im.warnf("%s: %s parameter %q dropped", id, in, stringOf(param["name"]))
+			continue
+		}
+		s := mapValue(im.schema(param["schema"]))
+		if s == nil {
+			s = map[string]interface{}{"type": "string"}
+		}
+		if desc := strings.TrimSpace(stringOf(param["description"])); desc != "" {
+			s["description"] = desc
+		}
+		if in != "path" {
+			if required, _ := param["required"].(bool); required {
+				s["required"] = true
+			}
+		}
+		setPair(target, stringOf(param["name"]), valueNode(s))
+	}
+
+	var requestExamples map[string]interface{}
+	if body := mapValue(im.deref(op["requestBody"])); body != nil {
+		contentType, media := pickContent(mapValue(body["content"]))
+		if media != nil {
+			appendPair(request, "content_type", scalarNode(contentType))
+			if s := im.schema(media["schema"]); s != nil {
+				appendPair(request, "schema", valueNode(s))
+			}
+			requestExamples = mediaExamples(media)
+		}
+	}
+
+	for _, key := range []struct{ in, name string }{{"path", "path_params"}, {"query", "query_params"}, {"header", "headers"}} {
+		if len(params[key.in].Content) > 0 {
+			appendPair(request, key.name, params[key.in])
+		}
+	}
+	if len(request.Content) > 0 {
+		appendPair(ep, "request", request)
+	}
+
+	response := mappingNode()
+	responseExamples := make(map[string]map[string]interface{})
+	responses := mapValue(op["responses"])
+	for _, code := range sortedKeys(responses) {
+		if _, err := strconv.Atoi(code); err != nil && code != "default" {
+			im.warnf("%s: response %s dropped (status code ranges are not supported)", id, code)
+			continue
+		}
+		resp := mapValue(im.deref(responses[code]))
+		entry := mappingNode()
+		if desc := strings.TrimSpace(stringOf(resp["description"])); desc != "" {
+			appendPair(entry, "description", scalarNode(desc))
+		}
+		if _, media := pickContent(mapValue(resp["content"])); media != nil {
+			if s := im.schema(media["schema"]); s != nil {
+				appendPair(entry, "schema", valueNode(s))
+			}
+			if examples := mediaExamples(media); len(examples) > 0 {
+				responseExamples[code] = examples
+			}
+		}
+		response.Content = append(response.Content, statusNode(code), entry)
+	}
+	appendPair(ep, "response", response)
+
+	intent := strings.TrimSpace(stringOf(op["description"]))
+	if intent == "" {
+		intent = TodoIntent
+	}
+	semantics := mappingNode("intent", scalarNode(intent))
+	if examples := buildExamples(requestExamples, responseExamples); len(examples.Content) > 0 {
+		appendPair(semantics, "examples", examples)
+	}
+	appendPair(ep, "semantics", semantics)
+
+	return ep, nil
+}
+
+// buildExamples pairs request and response examples by name.
+// Response examples are matched with the request example of the same name.
+func buildExamples(requestExamples map[string]interface{}, responseExamples map[string]map[string]interface{}) *yaml.Node {
+	out := sequenceNode()
+	used := make(map[string]bool)
+	names := make(map[string]bool)
+
+	for _, code := range sortedKeys(responseExamples) {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			continue
+		}
+		for _, name := range sortedKeys(responseExamples[code]) {
+			exampleName := name
+			if names[exampleName] {
+				exampleName = name + "_" + code
+			}
+			names[exampleName] = true
+
+			ex := mappingNode("name", scalarNode(exampleName))
+			if body, ok := requestExamples[name]; ok && !used[name] {
+				appendPair(ex, "request", exampleRequest(body))
+				used[name] = true
+			}
+			appendPair(ex, "response", mappingNode(
+				"status", valueNode(status),
+				"body", valueNode(responseExamples[code][name]),
+			))
+			out.Content = append(out.Content, ex)
+		}
+	}
+
+	for _, name := range sortedKeys(requestExamples) {
+		if used[name] || names[name] {
+			continue
+		}
+		out.Content = append(out.Content, mappingNode(
+			"name", scalarNode(name),
+			"request", exampleRequest(requestExamples[name]),
+		))
+	}
+	return out
+}
+
+// exampleRequest writes a request body example in the flat form of the
+// contracts (body fields directly under request). Bodies that are not objects,
+// or whose fields would be read as path, query or headers, keep a body key.
+func exampleRequest(body interface{}) *yaml.Node {
+	fields, ok := body.(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return mappingNode("body", valueNode(body))
+	}
+	for key := range fields {
+		if requestParts[key] {
+			return mappingNode("body", valueNode(body))
+		}
+	}
+	return valueNode(fields)
+}
+
+// mediaExamples collects the examples of a media type object, named by their
+// summary (the example name in exported contracts) or else their key
+func mediaExamples(media map[string]interface{}) map[string]interface{} {
+	examples := make(map[string]interface{})
+	for name, raw := range mapValue(media["examples"]) {
+		example := mapValue(raw)
+		if value, ok := example["value"]; ok {
+			if summary := strings.TrimSpace(stringOf(example["summary"])); summary != "" {
+				name = summary
+			}
+			examples[name] = value
+		}
+	}
+	if value, ok := media["example"]; ok {
+		examples["example"] = value
+	}
+	return examples
+}
+
+// pickContent prefers application/json and falls back to the first content type
+func pickContent(content map[string]interface{}) (string, map[string]interface{}) {
+	if media, ok := content["application/json"]; ok {
+		return "application/json", mapValue(media)
+	}
+	for _, contentType := range sortedKeys(content) {
+		return contentType, mapValue(content[contentType])
+	}
+	return "", nil
+}
+
+// deref resolves a local $ref to components (parameters, requestBodies, responses)
+func (im *importer) deref(raw interface{}) interface{} {
+	m := mapValue(raw)
+	ref, ok := m["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#/components/") {
+		return raw
+	}
+	parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+	if len(parts) != 2 {
+		return raw
+	}
+	target, ok := mapValue(mapValue(im.spec["components"])[parts[0]])[parts[1]]
+	if !ok {
+		im.warnf("dangling $ref %q", ref)
+		return nil
+	}
+	return im.deref(target)
+}
+
+// schema converts a JSON Schema into the contract schema format:
+// $ref to components.schemas becomes #/types/..., type arrays with "null" and
+// anyOf/oneOf with a null branch become nullable, and examples become example.
+func (im *importer) schema(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = value
+		}
+
+		if ref, ok := out["$ref"].(string); ok {
+			if strings.HasPrefix(ref, componentsPrefix) {
+				out["$ref"] = "#/types/" + strings.TrimPrefix(ref, componentsPrefix)
+			} else {
+				im.warnf("unsupported $ref %q kept as-is", ref)
+			}
+		}
+
+		if types, ok := out["type"].([]interface{}); ok {
+			var nonNull []interface{}
+			for _, t := range types {
+				if t == "null" {
+					out["nullable"] = true
+				} else {
+					nonNull = append(nonNull, t)
+				}
+			}
+			if len(nonNull) == 1 {
+				out["type"] = nonNull[0]
+			} else {
+				out["type"] = nonNull
+			}
+		}
+
+		for _, key := range []string{"anyOf", "oneOf"} {
+			branches, ok := out[key].([]interface{})
+			if !ok || len(branches) != 2 {
+				continue
+			}
+			for i, branch := range branches {
+				if mapValue(branch)["type"] == "null" {
+					other := mapValue(im.schema(branches[1-i]))
+					delete(out, key)
+					for k, val := range other {
+						out[k] = val
+					}
+					out["nullable"] = true
+					break
+				}
+			}
+		}
+
+		if examples, ok := out["examples"].([]interface{}); ok {
+			delete(out, "examples")
+			if len(examples) > 0 {
+				out["example"] = examples[0]
+			}
+		}
+
+		for key, value := range out {
+			if key == "example" || key == "enum" || key == "default" {
+				continue
+			}
+			out[key] = im.schema(value)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = im.schema(item)
+		}
+		return out
+	}
+	return raw
+}
+
+func mapValue(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func stringOf(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// YAML node helpers (used to control key order in the generated contract)
+
+func scalarNode(value string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(value, "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+	return n
+}
+
+// statusNode encodes a status code as a plain integer key ("default" stays a string)
+func statusNode(code string) *yaml.Node {
+	if _, err := strconv.Atoi(code); err == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: code}
+	}
+	return scalarNode(code)
+}
+
+func quotedNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle}
+}
+
+func mappingNode(pairs ...interface{}) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		appendPair(n, pairs[i].(string), pairs[i+1].(*yaml.Node))
+	}
+	return n
+}
+
+func sequenceNode(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: items}
+}
+
+// emptySequenceNode encodes as []
+func emptySequenceNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+}
+
+func appendPair(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, scalarNode(key), value)
+}
+
+// setPair replaces the value of an existing key or appends a new pair
+func setPair(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	appendPair(mapping, key, value)
+}
+
+// valueNode encodes an arbitrary decoded value with sorted mapping keys
+func valueNode(v interface{}) *yaml.Node {
+	switch val := v.(type) {
+	case *yaml.Node:
+		return val
+	case map[string]interface{}:
+		n := mappingNode()
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			appendPair(n, k, valueNode(val[k]))
+		}
+		return n
+	case []interface{}:
+		if len(val) == 0 {
+			return emptySequenceNode()
+		}
+		items := make([]*yaml.Node, len(val))
+		for i, item := range val {
+			items[i] = valueNode(item)
+		}
+		return sequenceNode(items...)
+	}
+
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return scalarNode(fmt.Sprintf("%v", v))
+	}
+	return &n
+}
@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal encodes a document as "yaml" or "json"
+func Marshal(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case "yaml", "":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI YAML: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI YAML: %w", err)
+		}
+		return buf.Bytes(), nil
+	case "json":
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI JSON: %w", err)
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected yaml or json)", format)
+	}
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// EndpointSchemas holds the resolved schemas of a single endpoint
+type EndpointSchemas struct {
+	ID          string
+	Method      string
+	Path        string
+	Request     *Schema            // Request body (nil if none)
+	QueryParams *Schema            // Object schema of query parameters (nil if none)
+	PathParams  *Schema            // Object schema of path parameters (nil if none)
+	Responses   map[string]*Schema // Status code -> body schema (nil value = no body)
+}
+
+// ContractSchemas holds every resolved schema of an object contract
+type ContractSchemas struct {
+	Types     map[string]*Schema
+	Endpoints []EndpointSchemas
+}
+
+// ResolveObject resolves all types and endpoint schemas of an object contract.
+// Every problem (dangling $ref, cycle, malformed schema) is collected and
+// returned as a joined error alongside whatever could be resolved.
+func ResolveObject(obj *types.ObjectDefinition) (*ContractSchemas, error) {
+	resolver := NewResolver(obj.Types).WithLibraries(obj.ImportedTypes)
+	result := &ContractSchemas{
+		Types: make(map[string]*Schema, len(obj.Types)),
+	}
+	var errs []error
+
+	typeNames := make([]string, 0, len(obj.Types))
+	for name := range obj.Types {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+
+	for _, name := range typeNames {
+		s, err := resolver.Type(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("types.%s: %w", name, err))
+			continue
+		}
+		result.Types[name] = s
+	}
+
+	for _, ep := range obj.API.Endpoints {
+		eps := EndpointSchemas{
+			ID:        ep.ID,
+			Method:    ep.Method,
+			Path:      ep.Path,
+			Responses: make(map[string]*Schema),
+		}
+		location := fmt.Sprintf("endpoint %s", ep.ID)
+
+		if raw, ok := ep.Request["schema"]; ok {
+			s, err := resolver.Resolve(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s request: %w", location, err))
+			}
+			eps.Request = s
+		}
+
+		if raw, ok := ep.Request["query_params"]; ok {
+			s, err := resolver.Resolve(map[string]interface{}{"properties": raw})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s query_params: %w", location, err))
+			}
+			eps.QueryParams = s
+		}
+
+		if raw, ok := ep.Request["path_params"]; ok {
+			s, err := resolver.Resolve(map[string]interface{}{"properties": raw})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s path_params: %w", location, err))
+			} else {
+				// Path parameters are always required
+				s.Required = s.PropertyNames()
+			}
+			eps.PathParams = s
+		}
+
+		codes := make([]string, 0, len(ep.Response))
+		for code := range ep.Response {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		for _, code := range codes {
+			respMap, ok := toMap(ep.Response[code])
+			if !ok {
+				eps.Responses[code] = nil
+				continue
+			}
+			rawSchema, ok := respMap["schema"]
+			if !ok {
+				eps.Responses[code] = nil
+				continue
+			}
+			s, err := resolver.Resolve(rawSchema)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s response %s: %w", location, code, err))
+			}
+			eps.Responses[code] = s
+		}
+
+		result.Endpoints = append(result.Endpoints, eps)
+	}
+
+	return result, errors.Join(errs...)
+}
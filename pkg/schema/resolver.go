@@ -0,0 +1,226 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/staka121/potter/pkg/types"
+)
+
+// localTypesPrefix is the prefix of references to the contract's own types
+const localTypesPrefix = "#/types/"
+
+// DanglingRefError reports a $ref whose target type does not exist
+type DanglingRefError struct {
+	Ref  string
+	File string // Imported library ("" for the contract's own types)
+	Name string
+}
+
+func (e *DanglingRefError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("dangling $ref %q: type %s is not defined in %s", e.Ref, e.Name, e.File)
+	}
+	return fmt.Sprintf("dangling $ref %q: type %s is not defined", e.Ref, e.Name)
+}
+
+// CycleError reports a chain of $ref that loops back on itself
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("circular $ref: %s", strings.Join(e.Path, " → "))
+}
+
+// ParseRef splits a reference into its library file and type name.
+// "#/types/Todo" refers to a local type (file is empty) and
+// "common.types.yaml#/types/Error" to a type of an imported library.
+func ParseRef(ref string) (file, name string, err error) {
+	hash := strings.Index(ref, "#")
+	if hash < 0 || !strings.HasPrefix(ref[hash:], localTypesPrefix) {
+		return "", "", fmt.Errorf("unsupported $ref %q (expected \"%s<Name>\" or \"<file>%s<Name>\")", ref, localTypesPrefix, localTypesPrefix)
+	}
+	file = ref[:hash]
+	name = strings.TrimPrefix(ref[hash:], localTypesPrefix)
+	if name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid $ref %q", ref)
+	}
+	return file, name, nil
+}
+
+// qualifiedName identifies a type across the contract and its imported libraries
+func qualifiedName(file, name string) string {
+	if file == "" {
+		return name
+	}
+	return file + "#" + name
+}
+
+// Resolver resolves $ref references against a contract's types map and the
+// libraries it imports. Resolved schemas are cached and shared; callers must
+// treat them as read-only.
+type Resolver struct {
+	typeDefs  map[string]types.TypeDef
+	libraries map[string]map[string]types.TypeDef
+	resolved  map[string]*Schema
+}
+
+// NewResolver creates a resolver for the given types map
+func NewResolver(typeDefs map[string]types.TypeDef) *Resolver {
+	return &Resolver{
+		typeDefs: typeDefs,
+		resolved: make(map[string]*Schema),
+	}
+}
+
+// WithLibraries registers imported type libraries keyed by import path
+func (r *Resolver) WithLibraries(libraries map[string]map[string]types.TypeDef) *Resolver {
+	r.libraries = libraries
+	return r
+}
+
+// Type returns the fully resolved schema of a named type
+func (r *Resolver) Type(name string) (*Schema, error) {
+	return r.resolveType("", name, localTypesPrefix+name, nil)
+}
+
+// LibraryType returns the fully resolved schema of a type in an imported library
+func (r *Resolver) LibraryType(file, name string) (*Schema, error) {
+	return r.resolveType(file, name, file+localTypesPrefix+name, nil)
+}
+
+// Resolve parses a raw schema and resolves every $ref it contains
+func (r *Resolver) Resolve(raw interface{}) (*Schema, error) {
+	s, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return r.resolveRefs(s, "", nil)
+}
+
+func (r *Resolver) resolveType(file, name, ref string, stack []string) (*Schema, error) {
+	key := qualifiedName(file, name)
+	if s, ok := r.resolved[key]; ok {
+		return s, nil
+	}
+
+	for i, seen := range stack {
+		if seen == key {
+			path := append(append([]string{}, stack[i:]...), key)
+			return nil, &CycleError{Path: path}
+		}
+	}
+
+	typeDefs := r.typeDefs
+	if file != "" {
+		library, ok := r.libraries[file]
+		if !ok {
+			return nil, fmt.Errorf("$ref %q: %s is not imported", ref, file)
+		}
+		typeDefs = library
+	}
+
+	def, ok := typeDefs[name]
+	if !ok {
+		return nil, &DanglingRefError{Ref: ref, File: file, Name: name}
+	}
+
+	s, err := Parse(typeDefToRaw(def))
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %w", key, err)
+	}
+
+	s, err = r.resolveRefs(s, file, append(stack, key))
+	if err != nil {
+		return nil, err
+	}
+
+	r.resolved[key] = s
+	return s, nil
+}
+
+// resolveRefs replaces $ref nodes in a schema tree with the referenced type.
+// Local references inside an imported library refer to that library's types.
+func (r *Resolver) resolveRefs(s *Schema, file string, stack []string) (*Schema, error) {
+	if s.Ref != "" {
+		refFile, name, err := ParseRef(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if refFile == "" {
+			refFile = file
+		}
+		target, err := r.resolveType(refFile, name, s.Ref, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		out := *target
+		out.Ref = s.Ref
+		if s.Description != "" {
+			out.Description = s.Description
+		}
+		if s.Nullable {
+			out.Nullable = true
+		}
+		return &out, nil
+	}
+
+	for name, prop := range s.Properties {
+		resolved, err := r.resolveRefs(prop, file, stack)
+		if err != nil {
+			return nil, err
+		}
+		s.Properties[name] = resolved
+	}
+
+	if s.Items != nil {
+		resolved, err := r.resolveRefs(s.Items, file, stack)
+		if err != nil {
+			return nil, err
+		}
+		s.Items = resolved
+	}
+
+	return s, nil
+}
+
+// ParseType converts a named type definition into a Schema without resolving $ref
+func ParseType(def types.TypeDef) (*Schema, error) {
+	return Parse(typeDefToRaw(def))
+}
+
+// typeDefToRaw converts a TypeDef back into the raw map form accepted by Parse
+func typeDefToRaw(def types.TypeDef) map[string]interface{} {
+	raw := map[string]interface{}{
+		"description": def.Description,
+	}
+	if def.Type != "" {
+		raw["type"] = def.Type
+	}
+	if def.Format != "" {
+		raw["format"] = def.Format
+	}
+	if len(def.Required) > 0 {
+		required := make([]interface{}, len(def.Required))
+		for i, r := range def.Required {
+			required[i] = r
+		}
+		raw["required"] = required
+	}
+	if len(def.Enum) > 0 {
+		enum := make([]interface{}, len(def.Enum))
+		for i, e := range def.Enum {
+			enum[i] = e
+		}
+		raw["enum"] = enum
+	}
+	if def.Items != nil {
+		raw["items"] = def.Items
+	}
+	if def.Properties != nil {
+		raw["properties"] = def.Properties
+	}
+	return raw
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is a typed model of the JSON-Schema-like subset used in contracts
+type Schema struct {
+	Ref         string // Original $ref (empty if the schema was defined inline)
+	Type        string // "object", "array", "string", "integer", "number", "boolean"
+	Description string
+	Format      string // e.g. "uuid", "email", "date-time"
+	Enum        []string
+	Required    []string           // Required property names (object only)
+	Properties  map[string]*Schema // Object properties
+	Items       *Schema            // Array item schema
+	MinLength   *int
+	MaxLength   *int
+	Nullable    bool
+	Example     interface{}
+}
+
+// IsRequired reports whether the named property is required
+func (s *Schema) IsRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PropertyNames returns the property names in sorted order
+func (s *Schema) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse converts a raw decoded YAML value into a Schema without resolving $ref.
+// A schema containing only $ref is returned with Ref set and no other fields.
+func Parse(raw interface{}) (*Schema, error) {
+	m, ok := toMap(raw)
+	if !ok {
+		return nil, fmt.Errorf("schema must be a mapping, got %T", raw)
+	}
+
+	s := &Schema{}
+
+	if ref, exists := m["$ref"]; exists {
+		refStr, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("$ref must be a string, got %T", ref)
+		}
+		s.Ref = refStr
+	}
+
+	s.Type = stringValue(m["type"])
+	s.Description = strings.TrimSpace(stringValue(m["description"]))
+	s.Format = stringValue(m["format"])
+	s.Example = m["example"]
+
+	if nullable, ok := m["nullable"].(bool); ok {
+		s.Nullable = nullable
+	}
+
+	if enum, exists := m["enum"]; exists {
+		values, ok := enum.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("enum must be a list, got %T", enum)
+		}
+		for _, v := range values {
+			s.Enum = append(s.Enum, fmt.Sprintf("%v", v))
+		}
+	}
+
+	if required, exists := m["required"]; exists {
+		switch r := required.(type) {
+		case []interface{}:
+			for _, v := range r {
+				s.Required = append(s.Required, fmt.Sprintf("%v", v))
+			}
+		case bool:
+			// Property-level "required: true" is handled by the parent object
+		default:
+			return nil, fmt.Errorf("required must be a list, got %T", required)
+		}
+	}
+
+	var err error
+	if s.MinLength, err = intValue(m, "minLength"); err != nil {
+		return nil, err
+	}
+	if s.MaxLength, err = intValue(m, "maxLength"); err != nil {
+		return nil, err
+	}
+
+	if props, exists := m["properties"]; exists {
+		propMap, ok := toMap(props)
+		if !ok {
+			return nil, fmt.Errorf("properties must be a mapping, got %T", props)
+		}
+		s.Properties = make(map[string]*Schema, len(propMap))
+		for name, rawProp := range propMap {
+			prop, err := Parse(rawProp)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+			s.Properties[name] = prop
+
+			// Support property-level "required: true"
+			if propMap, ok := toMap(rawProp); ok {
+				if req, ok := propMap["required"].(bool); ok && req && !s.IsRequired(name) {
+					s.Required = append(s.Required, name)
+				}
+			}
+		}
+		sort.Strings(s.Required)
+		if s.Type == "" {
+			s.Type = "object"
+		}
+	}
+
+	if items, exists := m["items"]; exists {
+		itemSchema, err := Parse(items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		s.Items = itemSchema
+		if s.Type == "" {
+			s.Type = "array"
+		}
+	}
+
+	return s, nil
+}
+
+// toMap converts decoded YAML mappings into map[string]interface{}
+func toMap(raw interface{}) (map[string]interface{}, bool) {
+	switch m := raw.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprintf("%v", k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func stringValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func intValue(m map[string]interface{}, key string) (*int, error) {
+	raw, exists := m[key]
+	if !exists {
+		return nil, nil
+	}
+	switch v := raw.(type) {
+	case int:
+		return &v, nil
+	case float64:
+		n := int(v)
+		return &n, nil
+	}
+	return nil, fmt.Errorf("%s must be an integer, got %T", key, raw)
+}
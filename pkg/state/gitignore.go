@@ -0,0 +1,42 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureGitignore makes git ignore patterns in dir. Patterns that dir/.gitignore
+// does not list yet are appended under a comment; existing rules are kept.
+func EnsureGitignore(dir, comment string, patterns ...string) error {
+	path := filepath.Join(dir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		listed[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, pattern := range patterns {
+		if !listed[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "# " + comment + "\n" + strings.Join(missing, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -1,19 +1,28 @@
 package state
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
+	"github.com/staka121/potter/internal/parser"
 	"github.com/staka121/potter/pkg/types"
 )
 
 const stateVersion = "1"
 const stateFileName = "state.json"
 const stateDirName = ".potter"
+const usageFileName = "usage.json"
+const secretsFileName = "secrets.json"
+const buildsFileName = "builds.json"
+
+// maxUsageRecords bounds the usage history kept on disk
+const maxUsageRecords = 500
 
 // Manager manages potter state for a tsubo project
 type Manager struct {
@@ -74,6 +83,144 @@ func (m *Manager) Save(state *types.PotterState) error {
 	return nil
 }
 
+// LoadUsage returns the recorded token usage of past implementations (oldest first)
+func (m *Manager) LoadUsage() ([]types.UsageRecord, error) {
+	data, err := os.ReadFile(filepath.Join(m.GetStateDir(), usageFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage history: %w", err)
+	}
+
+	var records []types.UsageRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse usage history: %w", err)
+	}
+
+	return records, nil
+}
+
+// AppendUsage adds records to the usage history
+func (m *Manager) AppendUsage(records ...types.UsageRecord) error {
+	history, err := m.LoadUsage()
+	if err != nil {
+		return err
+	}
+
+	history = append(history, records...)
+	if len(history) > maxUsageRecords {
+		history = history[len(history)-maxUsageRecords:]
+	}
+
+	if err := os.MkdirAll(m.GetStateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize usage history: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(m.GetStateDir(), usageFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage history: %w", err)
+	}
+
+	return nil
+}
+
+// LoadBuilds returns the build records of successfully implemented objects, keyed by object name
+func (m *Manager) LoadBuilds() (map[string]types.BuildRecord, error) {
+	builds := make(map[string]types.BuildRecord)
+
+	data, err := os.ReadFile(filepath.Join(m.GetStateDir(), buildsFileName))
+	if os.IsNotExist(err) {
+		return builds, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build records: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &builds); err != nil {
+		return nil, fmt.Errorf("failed to parse build records: %w", err)
+	}
+
+	return builds, nil
+}
+
+// SaveBuilds writes the build records
+func (m *Manager) SaveBuilds(builds map[string]types.BuildRecord) error {
+	if err := os.MkdirAll(m.GetStateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(builds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize build records: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(m.GetStateDir(), buildsFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write build records: %w", err)
+	}
+
+	return nil
+}
+
+// Secret returns the generated secret stored under key, creating and saving a
+// random one on first use. Secrets are kept in .potter/secrets.json (mode 0600)
+// so database credentials stay stable across runs; .potter/.gitignore keeps the
+// file out of git.
+func (m *Manager) Secret(key string) (string, error) {
+	path := filepath.Join(m.GetStateDir(), secretsFileName)
+
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &secrets); err != nil {
+			return "", fmt.Errorf("failed to parse secrets file: %w", err)
+		}
+		// Also covers secrets files written before the .gitignore was
+		if err := m.ignoreSecrets(); err != nil {
+			return "", err
+		}
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	if secret, ok := secrets[key]; ok {
+		return secret, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	secrets[key] = fmt.Sprintf("%x", buf)
+
+	if err := os.MkdirAll(m.GetStateDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := m.ignoreSecrets(); err != nil {
+		return "", err
+	}
+	data, err = json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize secrets: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	return secrets[key], nil
+}
+
+// ignoreSecrets keeps the secrets file out of git. The rest of the state
+// directory (state, history) is meant to be committed.
+func (m *Manager) ignoreSecrets() error {
+	return EnsureGitignore(m.GetStateDir(), "Generated database passwords: never commit", secretsFileName)
+}
+
 // ComputeHash computes the SHA256 hash of a file's contents
 func (m *Manager) ComputeHash(filePath string) (string, error) {
 	data, err := os.ReadFile(filePath)
@@ -85,6 +232,62 @@ func (m *Manager) ComputeHash(filePath string) (string, error) {
 	return fmt.Sprintf("%x", sum), nil
 }
 
+// ContractSnapshot holds a contract and the type libraries it imports as read from disk
+type ContractSnapshot struct {
+	Hash     string
+	Contract string
+	Imports  map[string]string // Import path -> library YAML
+}
+
+// SnapshotContract reads a contract and every library it imports.
+// The hash covers the contract and all imported libraries, so changing a
+// shared type changes the hash of every contract that imports it. A contract
+// without imports hashes the same as ComputeHash.
+func (m *Manager) SnapshotContract(contractPath string) (*ContractSnapshot, error) {
+	data, err := os.ReadFile(contractPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract: %w", err)
+	}
+
+	object, err := parser.ParseObjectYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ContractSnapshot{
+		Contract: string(data),
+		Imports:  make(map[string]string, len(object.Imports)),
+	}
+
+	h := sha256.New()
+	h.Write(data)
+
+	imports := append([]string{}, object.Imports...)
+	sort.Strings(imports)
+	for _, imp := range imports {
+		libData, err := os.ReadFile(parser.ImportPath(contractPath, imp))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import %s: %w", imp, err)
+		}
+		snapshot.Imports[imp] = string(libData)
+
+		h.Write([]byte("\x00" + imp + "\x00"))
+		h.Write(libData)
+	}
+
+	snapshot.Hash = fmt.Sprintf("%x", h.Sum(nil))
+	return snapshot, nil
+}
+
+// ComputeContractHash computes the hash of a contract and its imported libraries
+func (m *Manager) ComputeContractHash(contractPath string) (string, error) {
+	snapshot, err := m.SnapshotContract(contractPath)
+	if err != nil {
+		return "", err
+	}
+	return snapshot.Hash, nil
+}
+
 // Initialize creates the initial state from the current contracts
 func (m *Manager) Initialize(tsubo *types.TsuboDefinition, contractsDir string) (*types.PotterState, error) {
 	tsuboHash, err := m.ComputeHash(m.tsuboFilePath)
@@ -100,20 +303,16 @@ func (m *Manager) Initialize(tsubo *types.TsuboDefinition, contractsDir string)
 			contractPath = filepath.Join(contractsDir, obj.Contract)
 		}
 
-		hash, err := m.ComputeHash(contractPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to hash contract for %s: %w", obj.Name, err)
-		}
-
-		snapshot, err := os.ReadFile(contractPath)
+		snapshot, err := m.SnapshotContract(contractPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read contract for %s: %w", obj.Name, err)
+			return nil, fmt.Errorf("failed to snapshot contract for %s: %w", obj.Name, err)
 		}
 
 		services[obj.Name] = &types.ServiceState{
 			ContractFile:     obj.Contract,
-			ContractHash:     hash,
-			ContractSnapshot: string(snapshot),
+			ContractHash:     snapshot.Hash,
+			ContractSnapshot: snapshot.Contract,
+			ImportSnapshots:  snapshot.Imports,
 			LastMigrated:     time.Now(),
 			MigrationVersion: 0,
 		}
@@ -13,6 +13,7 @@ type ArchitectureSpec struct {
 	DirectoryStructure []DirectoryEntry `yaml:"directory_structure"`
 	Rules              []string         `yaml:"rules"`
 	Notes              string           `yaml:"notes"`
+	AllowedFiles       []string         `yaml:"allowed_files"` // Globs of the files generated code may write (empty = any)
 }
 
 // DirectoryEntry represents a directory in the architecture's directory structure
@@ -0,0 +1,32 @@
+package types
+
+// ProjectConfig represents the project configuration file (potter.yaml)
+type ProjectConfig struct {
+	ProjectRoot        string   `yaml:"project_root"`
+	ContextFiles       []string `yaml:"context_files"`
+	ImplementationsDir string   `yaml:"implementations_dir"`
+	ArtifactsDir       string   `yaml:"artifacts_dir"`
+	Concurrency        int      `yaml:"concurrency"` // 0 = unlimited
+	Schedule           string   `yaml:"schedule"`    // "dag" (default) or "waves"
+	Model              string   `yaml:"model"`
+	Provider           string   `yaml:"provider"`      // "claude" (default) or "openai"
+	BaseURL            string   `yaml:"base_url"`      // LLM API base URL (proxy or local model server)
+	MaxAttempts        int      `yaml:"max_attempts"`  // API calls per request incl. retries (0 = default)
+	RepairRounds       int      `yaml:"repair_rounds"` // Follow-up turns fixing failed checks (0 = default, -1 = none)
+	SkipChecks         bool     `yaml:"skip_checks"`   // Don't build/vet generated code
+	RunTests           bool     `yaml:"run_tests"`     // Also run the generated tests
+	Registry           string   `yaml:"registry"`
+	Namespace          string   `yaml:"namespace"`
+
+	// Pricing overrides the built-in price table, keyed by model name (or prefix)
+	Pricing map[string]ModelPrice `yaml:"pricing"`
+
+	// Path is the potter.yaml this config was loaded from (empty if none was found)
+	Path string `yaml:"-"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
@@ -4,11 +4,16 @@ package types
 type ObjectDefinition struct {
 	Version      string             `yaml:"version"`
 	BelongsTo    string             `yaml:"belongs_to"`
+	Imports      []string           `yaml:"imports"` // Shared .types.yaml libraries, relative to the contract
 	Service      ServiceConfig      `yaml:"service"`
 	API          APIConfig          `yaml:"api"`
 	Types        map[string]TypeDef `yaml:"types"`
 	Dependencies DependenciesConfig `yaml:"dependencies"`
 	Performance  PerformanceConfig  `yaml:"performance"`
+
+	// ImportedTypes holds the types of each imported library, keyed by import path.
+	// It is filled by the parser when imports are resolved.
+	ImportedTypes map[string]map[string]TypeDef `yaml:"-"`
 }
 
 // ServiceConfig contains service metadata
@@ -37,19 +42,70 @@ type APIConfig struct {
 
 // Endpoint represents an API endpoint
 type Endpoint struct {
-	ID       string                 `yaml:"id"`
-	Method   string                 `yaml:"method"`
-	Path     string                 `yaml:"path"`
-	Request  map[string]interface{} `yaml:"request"`
-	Response map[string]interface{} `yaml:"response"`
+	ID          string                 `yaml:"id"`
+	Method      string                 `yaml:"method"`
+	Path        string                 `yaml:"path"`
+	Description string                 `yaml:"description"`
+	Request     map[string]interface{} `yaml:"request"`
+	Response    map[string]interface{} `yaml:"response"`
+	Semantics   Semantics              `yaml:"semantics"`
+}
+
+// Semantics describes the intended behavior of an endpoint (instructions for AI)
+type Semantics struct {
+	Intent   string    `yaml:"intent"`
+	Behavior Behavior  `yaml:"behavior"`
+	Examples []Example `yaml:"examples"`
+}
+
+// Behavior describes the success path and edge cases of an endpoint
+type Behavior struct {
+	Success   string     `yaml:"success"`
+	EdgeCases []EdgeCase `yaml:"edge_cases"`
+}
+
+// EdgeCase describes how an endpoint responds to an exceptional input
+type EdgeCase struct {
+	Case     string      `yaml:"case"`
+	Response string      `yaml:"response"` // e.g. "400 Bad Request"
+	Body     interface{} `yaml:"body"`
+	Reason   string      `yaml:"reason"`
+	Status   int         `yaml:"-"` // Parsed from Response (0 if not specified)
+}
+
+// Example is a named request/response pair
+type Example struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Request     map[string]interface{} `yaml:"request"`
+	Response    ExampleResponse        `yaml:"response"`
+}
+
+// ExampleResponse is the expected response of an example
+type ExampleResponse struct {
+	Status int         `yaml:"status"`
+	Body   interface{} `yaml:"body"`
 }
 
 // TypeDef represents a type definition
+// Properties are kept raw; use pkg/schema to obtain a typed, $ref-resolved model.
 type TypeDef struct {
 	Description string                 `yaml:"description"`
+	Type        string                 `yaml:"type"`
+	Format      string                 `yaml:"format"`
+	Required    []string               `yaml:"required"`
+	Enum        []string               `yaml:"enum"`
+	Items       interface{}            `yaml:"items"`
 	Properties  map[string]interface{} `yaml:"properties"`
 }
 
+// TypeLibrary represents a shared .types.yaml file imported by object contracts
+type TypeLibrary struct {
+	Version     string             `yaml:"version"`
+	Description string             `yaml:"description"`
+	Types       map[string]TypeDef `yaml:"types"`
+}
+
 // PerformanceConfig defines SLA latency requirements
 type PerformanceConfig struct {
 	Latency LatencyConfig `yaml:"latency"`
@@ -74,6 +130,7 @@ type ServiceDependency struct {
 	Reason    string   `yaml:"reason"`
 	Endpoints []string `yaml:"endpoints"`
 	Type      string   `yaml:"type"`
+	External  bool     `yaml:"external"` // Provided outside the tsubo (not implemented by Potter)
 }
 
 // DatabaseDependency represents a database dependency
@@ -2,13 +2,19 @@ package types
 
 // ImplementationPlan represents the complete implementation plan
 type ImplementationPlan struct {
-	Tsubo              string   `json:"tsubo"`
-	TsuboFile          string   `json:"tsubo_file"`
-	ContractsDir       string   `json:"contracts_dir"`
-	ProjectRoot        string   `json:"project_root"`
-	ImplementationsDir string   `json:"implementations_dir"`
-	ContextFiles       []string `json:"context_files"`
-	Waves              []Wave   `json:"waves"`
+	Tsubo              string        `json:"tsubo"`
+	TsuboFile          string        `json:"tsubo_file"`
+	ContractsDir       string        `json:"contracts_dir"`
+	ProjectRoot        string        `json:"project_root"`
+	ImplementationsDir string        `json:"implementations_dir"`
+	ArtifactsDir       string        `json:"artifacts_dir,omitempty"` // prompts and responses are saved here
+	ContextFiles       []string      `json:"context_files"`
+	Network            NetworkConfig `json:"network"`
+	Waves              []Wave        `json:"waves"`
+
+	// Hashes taken when the plan was generated, used to reject stale plans
+	TsuboHash      string            `json:"tsubo_hash,omitempty"`
+	ContractHashes map[string]string `json:"contract_hashes,omitempty"` // object name -> contract hash (including imported libraries)
 }
 
 // Wave represents a group of objects that can be implemented in parallel
@@ -25,4 +31,6 @@ type ObjectInWave struct {
 	Dependencies []string `json:"dependencies"`
 	Port         int      `json:"port"`
 	IsGateway    bool     `json:"is_gateway"` // True if this is an auto-generated gateway service
+
+	Routes []GatewayRoute `json:"routes,omitempty"` // Routes of the gateway (gateway only)
 }
@@ -13,11 +13,12 @@ type PotterState struct {
 
 // ServiceState represents the state of a single service
 type ServiceState struct {
-	ContractFile     string    `json:"contract_file"`
-	ContractHash     string    `json:"contract_hash"`
-	ContractSnapshot string    `json:"contract_snapshot"` // Full YAML for diff analysis
-	LastMigrated     time.Time `json:"last_migrated"`
-	MigrationVersion int       `json:"migration_version"`
+	ContractFile     string            `json:"contract_file"`
+	ContractHash     string            `json:"contract_hash"`
+	ContractSnapshot string            `json:"contract_snapshot"`          // Full YAML for diff analysis
+	ImportSnapshots  map[string]string `json:"import_snapshots,omitempty"` // Import path -> full YAML of imported type libraries
+	LastMigrated     time.Time         `json:"last_migrated"`
+	MigrationVersion int               `json:"migration_version"`
 }
 
 // MigrationRecord represents a single migration event
@@ -36,3 +37,20 @@ type ChangeRecord struct {
 	Breaking    bool   `json:"breaking"`
 	Description string `json:"description"`
 }
+
+// BuildRecord records the build key of a successfully implemented object (.potter/builds.json)
+type BuildRecord struct {
+	Key     string    `json:"key"`
+	Model   string    `json:"model"`
+	BuiltAt time.Time `json:"built_at"`
+	Files   []string  `json:"files"` // Files written to the implementation directory
+}
+
+// UsageRecord records the tokens one AI implementation consumed (.potter/usage.json)
+type UsageRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Object       string    `json:"object"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+}
@@ -2,9 +2,13 @@ package types
 
 // TsuboDefinition represents the entire application (tsubo)
 type TsuboDefinition struct {
-	Version string       `yaml:"version"`
-	Tsubo   TsuboConfig  `yaml:"tsubo"`
-	Objects []ObjectRef  `yaml:"objects"`
+	Version          string            `yaml:"version"`
+	Tsubo            TsuboConfig       `yaml:"tsubo"`
+	Objects          []ObjectRef       `yaml:"objects"`
+	Gateway          GatewayConfig     `yaml:"gateway"`
+	Deployment       DeploymentConfig  `yaml:"deployment"`
+	IntegrationTests []IntegrationTest `yaml:"integration_tests"`
+	Metadata         Metadata          `yaml:"metadata"`
 }
 
 // TsuboConfig contains the tsubo metadata
@@ -16,10 +20,10 @@ type TsuboConfig struct {
 
 // ObjectRef references an object (domain/microservice) in the tsubo
 type ObjectRef struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	Contract    string   `yaml:"contract"`
-	Runtime     Runtime  `yaml:"runtime"`
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	Contract     string   `yaml:"contract"`
+	Runtime      Runtime  `yaml:"runtime"`
 	Dependencies []string `yaml:"dependencies"`
 }
 
@@ -29,3 +33,46 @@ type Runtime struct {
 	Port        int    `yaml:"port"`
 	HealthCheck string `yaml:"health_check"`
 }
+
+// GatewayConfig controls the API gateway generated as the tsubo's single entry point
+type GatewayConfig struct {
+	Enabled  *bool          `yaml:"enabled"`  // nil = generated when the tsubo has more than one object
+	Name     string         `yaml:"name"`     // Object name of the gateway (default: gateway-service)
+	Port     int            `yaml:"port"`     // Listening port (default: 8080)
+	Contract string         `yaml:"contract"` // Optional contract for gateway-level endpoints (e.g. aggregation routes)
+	Routes   []GatewayRoute `yaml:"routes"`   // Per-object route overrides
+}
+
+// GatewayRoute describes how the gateway exposes an object
+type GatewayRoute struct {
+	Service string   `yaml:"service" json:"service"`
+	Paths   []string `yaml:"paths" json:"paths,omitempty"`     // Path prefixes routed to the object (default: derived from its contract)
+	Exclude bool     `yaml:"exclude" json:"exclude,omitempty"` // Internal-only object, not reachable through the gateway
+}
+
+// DeploymentConfig defines how the tsubo is deployed locally
+type DeploymentConfig struct {
+	Orchestration string        `yaml:"orchestration"`
+	Network       NetworkConfig `yaml:"network"`
+	StartupOrder  []string      `yaml:"startup_order"` // Explicit start order (empty = derived from dependencies)
+}
+
+// NetworkConfig defines the shared Docker network
+type NetworkConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Driver string `yaml:"driver" json:"driver"`
+}
+
+// IntegrationTest describes a tsubo-wide test scenario
+type IntegrationTest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// Metadata contains descriptive information about the tsubo
+type Metadata struct {
+	Version    string            `yaml:"version"`
+	Created    string            `yaml:"created"`
+	Philosophy string            `yaml:"philosophy"`
+	Labels     map[string]string `yaml:"labels"` // Added to generated Kubernetes manifests
+}
@@ -0,0 +1,28 @@
+package validation
+
+// typesSchema describes the structure of a .types.yaml shared type library
+func typesSchema() *field {
+	return mapping(map[string]*field{
+		"version":     req(scalar()),
+		"description": scalar(),
+		"types":       req(dict(typeDefSchema())),
+	})
+}
+
+// ValidateTypesFile validates a .types.yaml shared type library.
+// Libraries may only reference their own types.
+func ValidateTypesFile(typesFile string) *Result {
+	result := &Result{}
+	rep := &reporter{file: typesFile, result: result}
+
+	root := loadDocument(typesFile, rep)
+	if root == nil {
+		return result
+	}
+
+	walk(rep, root, typesSchema(), "")
+	checkRefs(rep, root, nil)
+
+	result.Sort()
+	return result
+}
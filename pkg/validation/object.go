@@ -0,0 +1,412 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/staka121/potter/pkg/schema"
+	"github.com/staka121/potter/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// validHTTPMethods lists the HTTP methods accepted in api.endpoints
+var validHTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// latencyPattern matches latency thresholds such as "50ms", "1.5s" or "500us"
+var latencyPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)(us|ms|s)$`)
+
+// objectSchema describes the structure of a .object.yaml file
+func objectSchema() *field {
+	latency := withCheck(scalar(), checkLatency)
+
+	endpoint := mapping(map[string]*field{
+		"id":          req(scalar()),
+		"method":      req(withCheck(scalar(), checkHTTPMethod)),
+		"path":        req(withCheck(scalar(), checkPath)),
+		"description": scalar(),
+		"request": mapping(map[string]*field{
+			"content_type": scalar(),
+			"schema":       anything(),
+			"query_params": dict(anything()),
+			"path_params":  dict(anything()),
+			"headers":      dict(anything()),
+		}),
+		"response": dict(mapping(map[string]*field{
+			"description": scalar(),
+			"schema":      anything(),
+			"headers":     dict(anything()),
+		})),
+		"semantics": mapping(map[string]*field{
+			"intent": scalar(),
+			"behavior": mapping(map[string]*field{
+				"success": scalar(),
+				"edge_cases": sequence(mapping(map[string]*field{
+					"case":     req(scalar()),
+					"response": scalar(),
+					"body":     anything(),
+					"reason":   scalar(),
+				})),
+			}),
+			"examples": sequence(mapping(map[string]*field{
+				"name":        req(scalar()),
+				"description": scalar(),
+				"request":     openMapping(),
+				"response": mapping(map[string]*field{
+					"status": integer(),
+					"body":   anything(),
+				}),
+			})),
+		}),
+	})
+
+	return mapping(map[string]*field{
+		"version":    req(scalar()),
+		"belongs_to": scalar(),
+		"service": req(mapping(map[string]*field{
+			"name":         req(scalar()),
+			"description":  scalar(),
+			"architecture": scalar(),
+			"context": mapping(map[string]*field{
+				"purpose":          scalar(),
+				"domain":           scalar(),
+				"domain_boundary":  scalar(),
+				"responsibilities": sequence(scalar()),
+				"constraints":      sequence(scalar()),
+			}),
+		})),
+		"api": req(mapping(map[string]*field{
+			"version":   scalar(),
+			"base_path": withCheck(scalar(), checkPath),
+			"endpoints": req(sequence(endpoint)),
+		})),
+		"imports": sequence(scalar()),
+		"types":   dict(typeDefSchema()),
+		"dependencies": mapping(map[string]*field{
+			"services": sequence(mapping(map[string]*field{
+				"name":      req(scalar()),
+				"reason":    scalar(),
+				"endpoints": sequence(scalar()),
+				"type":      scalar(),
+				"external":  boolean(),
+			})),
+			"databases": sequence(mapping(map[string]*field{
+				"name":   req(scalar()),
+				"type":   req(scalar()),
+				"tables": sequence(scalar()),
+			})),
+		}),
+		"tests": openMapping(),
+		"performance": mapping(map[string]*field{
+			"latency": mapping(map[string]*field{
+				"p50": latency,
+				"p95": latency,
+				"p99": latency,
+			}),
+			"throughput": anything(),
+			"notes":      scalar(),
+		}),
+	})
+}
+
+// typeDefSchema describes a named type in types: (contracts and type libraries)
+func typeDefSchema() *field {
+	return mapping(map[string]*field{
+		"description": scalar(),
+		"type":        scalar(),
+		"properties":  openMapping(),
+		"required":    sequence(scalar()),
+		"enum":        sequence(scalar()),
+		"items":       anything(),
+		"format":      scalar(),
+	})
+}
+
+// ValidateObjectFile validates a .object.yaml contract file and the type libraries it imports
+func ValidateObjectFile(objectFile string) *Result {
+	result := &Result{}
+	rep := &reporter{file: objectFile, result: result}
+
+	root := loadDocument(objectFile, rep)
+	if root == nil {
+		return result
+	}
+
+	walk(rep, root, objectSchema(), "")
+	checkEndpoints(rep, lookup(lookup(root, "api"), "endpoints"))
+	checkArchitecture(rep, objectFile, lookup(lookup(root, "service"), "architecture"))
+	checkLatencyOrder(rep, lookup(lookup(root, "performance"), "latency"))
+	libraries := checkImports(rep, objectFile, lookup(root, "imports"))
+	checkRefs(rep, root, libraries)
+
+	result.Sort()
+	return result
+}
+
+// checkEndpoints detects duplicate endpoint IDs, duplicate routes and invalid response codes
+func checkEndpoints(rep *reporter, endpoints *yaml.Node) {
+	ids := make(map[string]*yaml.Node)
+	routes := make(map[string]*yaml.Node)
+
+	for i, ep := range sequenceItems(endpoints) {
+		if id := lookup(ep, "id"); id != nil && id.Value != "" {
+			if first, exists := ids[id.Value]; exists {
+				rep.errorf(id, "duplicate endpoint id %q (first defined at line %d)", id.Value, first.Line)
+			} else {
+				ids[id.Value] = id
+			}
+		}
+
+		method, path := lookup(ep, "method"), lookup(ep, "path")
+		if method != nil && path != nil && method.Value != "" && path.Value != "" {
+			route := strings.ToUpper(method.Value) + " " + path.Value
+			if first, exists := routes[route]; exists {
+				rep.errorf(method, "duplicate route %s (first defined at line %d)", route, first.Line)
+			} else {
+				routes[route] = method
+			}
+		}
+
+		response := lookup(ep, "response")
+		if response == nil || response.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(response.Content); j += 2 {
+			key := response.Content[j]
+			if !isStatusCode(key.Value) {
+				rep.errorf(key, "api.endpoints[%d].response: invalid status code %q", i, key.Value)
+			}
+		}
+	}
+}
+
+// checkArchitecture verifies that a referenced architecture file exists
+func checkArchitecture(rep *reporter, objectFile string, arch *yaml.Node) {
+	if arch == nil || arch.Kind != yaml.ScalarNode || arch.Value == "" {
+		return
+	}
+	archPath := filepath.Join(filepath.Dir(objectFile), arch.Value)
+	if _, err := os.Stat(archPath); err != nil {
+		rep.errorf(arch, "architecture file not found: %s", archPath)
+	}
+}
+
+// checkLatencyOrder warns when latency thresholds are not monotonically increasing
+func checkLatencyOrder(rep *reporter, latency *yaml.Node) {
+	var prevLabel string
+	var prev float64
+	for _, label := range []string{"p50", "p95", "p99"} {
+		n := lookup(latency, label)
+		if n == nil {
+			continue
+		}
+		secs, ok := parseLatency(n.Value)
+		if !ok {
+			continue
+		}
+		if prevLabel != "" && secs < prev {
+			rep.warnf(n, "performance.latency.%s (%s) is lower than %s", label, n.Value, prevLabel)
+		}
+		prevLabel, prev = label, secs
+	}
+}
+
+func checkHTTPMethod(n *yaml.Node) string {
+	for _, m := range validHTTPMethods {
+		if n.Value == m {
+			return ""
+		}
+	}
+	return fmt.Sprintf("invalid HTTP method %q (expected one of %s)", n.Value, strings.Join(validHTTPMethods, ", "))
+}
+
+func checkPath(n *yaml.Node) string {
+	if !strings.HasPrefix(n.Value, "/") {
+		return fmt.Sprintf("path %q must start with \"/\"", n.Value)
+	}
+	return ""
+}
+
+func checkLatency(n *yaml.Node) string {
+	if _, ok := parseLatency(n.Value); !ok {
+		return fmt.Sprintf("invalid latency %q (expected a positive number with unit us, ms or s, e.g. \"50ms\")", n.Value)
+	}
+	return ""
+}
+
+// parseLatency parses a latency string into seconds
+func parseLatency(s string) (float64, bool) {
+	m := latencyPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	switch m[2] {
+	case "us":
+		v /= 1_000_000
+	case "ms":
+		v /= 1000
+	}
+	return v, true
+}
+
+// isStatusCode reports whether a response key is a valid HTTP status code or "default"
+func isStatusCode(s string) bool {
+	if s == "default" {
+		return true
+	}
+	code, err := strconv.Atoi(s)
+	return err == nil && code >= 100 && code <= 599
+}
+
+// checkImports verifies that every imported type library exists and is valid.
+// It returns the types of each library that could be loaded, keyed by import path.
+func checkImports(rep *reporter, objectFile string, imports *yaml.Node) map[string]map[string]types.TypeDef {
+	libraries := make(map[string]map[string]types.TypeDef)
+	seen := make(map[string]*yaml.Node)
+
+	for _, imp := range sequenceItems(imports) {
+		if imp.Kind != yaml.ScalarNode || imp.Value == "" {
+			continue
+		}
+		if first, exists := seen[imp.Value]; exists {
+			rep.errorf(imp, "duplicate import %q (first at line %d)", imp.Value, first.Line)
+			continue
+		}
+		seen[imp.Value] = imp
+
+		libPath := imp.Value
+		if !filepath.IsAbs(libPath) {
+			libPath = filepath.Join(filepath.Dir(objectFile), libPath)
+		}
+		if _, err := os.Stat(libPath); err != nil {
+			rep.errorf(imp, "imported type library not found: %s", libPath)
+			continue
+		}
+
+		rep.result.Merge(ValidateTypesFile(libPath))
+
+		data, err := os.ReadFile(libPath)
+		if err != nil {
+			continue
+		}
+		var library types.TypeLibrary
+		if err := yaml.Unmarshal(data, &library); err != nil {
+			continue
+		}
+		libraries[imp.Value] = library.Types
+	}
+
+	return libraries
+}
+
+// checkRefs reports malformed and dangling $ref values and circular type references.
+// References into a library must name a file listed in imports; libraries that
+// failed to load are skipped. A nil libraries map validates a type library,
+// which may only reference its own types.
+func checkRefs(rep *reporter, root *yaml.Node, libraries map[string]map[string]types.TypeDef) {
+	typesNode := lookup(root, "types")
+	defined := make(map[string]*yaml.Node)
+	if typesNode != nil && typesNode.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(typesNode.Content); i += 2 {
+			defined[typesNode.Content[i].Value] = typesNode.Content[i]
+		}
+	}
+
+	imported := make(map[string]bool)
+	for _, imp := range sequenceItems(lookup(root, "imports")) {
+		imported[imp.Value] = true
+	}
+
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		n = resolve(n)
+		if n == nil {
+			return
+		}
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], resolve(n.Content[i+1])
+				if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+					checkRef(rep, value, defined, imported, libraries)
+					continue
+				}
+				visit(value)
+			}
+			return
+		}
+		for _, child := range n.Content {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	if typesNode == nil {
+		return
+	}
+	var typeDefs map[string]types.TypeDef
+	if err := typesNode.Decode(&typeDefs); err != nil {
+		return
+	}
+	resolver := schema.NewResolver(typeDefs).WithLibraries(libraries)
+	reported := make(map[string]bool)
+	for _, name := range sortedTypeNames(typeDefs) {
+		_, err := resolver.Type(name)
+		var cycle *schema.CycleError
+		if errors.As(err, &cycle) && !reported[cycle.Path[0]] {
+			for _, member := range cycle.Path {
+				reported[member] = true
+			}
+			rep.errorf(defined[cycle.Path[0]], "%v", cycle)
+		}
+	}
+}
+
+// checkRef validates a single $ref value against local types and imported libraries
+func checkRef(rep *reporter, value *yaml.Node, defined map[string]*yaml.Node, imported map[string]bool, libraries map[string]map[string]types.TypeDef) {
+	file, name, err := schema.ParseRef(value.Value)
+	if err != nil {
+		rep.errorf(value, "%v", err)
+		return
+	}
+
+	if file == "" {
+		if _, ok := defined[name]; !ok {
+			rep.errorf(value, "dangling $ref %q: type %s is not defined", value.Value, name)
+		}
+		return
+	}
+
+	if libraries == nil {
+		rep.errorf(value, "$ref %q: type libraries may only reference their own types", value.Value)
+		return
+	}
+	if !imported[file] {
+		rep.errorf(value, "$ref %q: %s is not listed in imports", value.Value, file)
+		return
+	}
+	library, loaded := libraries[file]
+	if !loaded {
+		return // Problems with the library itself are already reported
+	}
+	if _, ok := library[name]; !ok {
+		rep.errorf(value, "dangling $ref %q: type %s is not defined in %s", value.Value, name, file)
+	}
+}
+
+func sortedTypeNames(typeDefs map[string]types.TypeDef) []string {
+	names := make([]string, 0, len(typeDefs))
+	for name := range typeDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
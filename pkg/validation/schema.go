@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kind is the expected YAML node kind of a field
+type kind int
+
+const (
+	kindAny kind = iota
+	kindScalar
+	kindMapping
+	kindSequence
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindScalar:
+		return "scalar"
+	case kindMapping:
+		return "mapping"
+	case kindSequence:
+		return "sequence"
+	}
+	return "any"
+}
+
+// field describes the expected shape of a YAML node
+type field struct {
+	kind     kind
+	required bool
+	fields   map[string]*field // known keys of a mapping (nil = keys are not checked)
+	values   *field            // shape of every value in a mapping with arbitrary keys
+	items    *field            // shape of every item in a sequence
+	check    func(n *yaml.Node) string
+}
+
+// anything accepts any node
+func anything() *field { return &field{kind: kindAny} }
+
+// scalar expects a scalar value
+func scalar() *field { return &field{kind: kindScalar} }
+
+// integer expects an integer scalar
+func integer() *field {
+	return &field{kind: kindScalar, check: func(n *yaml.Node) string {
+		if _, err := strconv.Atoi(n.Value); err != nil {
+			return fmt.Sprintf("expected an integer, got %q", n.Value)
+		}
+		return ""
+	}}
+}
+
+// boolean expects a true/false scalar
+func boolean() *field {
+	return &field{kind: kindScalar, check: func(n *yaml.Node) string {
+		if n.ShortTag() != "!!bool" {
+			return fmt.Sprintf("expected true or false, got %q", n.Value)
+		}
+		return ""
+	}}
+}
+
+// mapping expects a mapping with the given known keys
+func mapping(fields map[string]*field) *field {
+	return &field{kind: kindMapping, fields: fields}
+}
+
+// dict expects a mapping with arbitrary keys whose values share one shape
+func dict(values *field) *field {
+	return &field{kind: kindMapping, values: values}
+}
+
+// openMapping expects a mapping whose keys are not checked
+func openMapping() *field { return &field{kind: kindMapping} }
+
+// sequence expects a sequence whose items share one shape
+func sequence(items *field) *field {
+	return &field{kind: kindSequence, items: items}
+}
+
+// req marks a field as required
+func req(f *field) *field {
+	f.required = true
+	return f
+}
+
+// withCheck attaches an additional check to a scalar field
+func withCheck(f *field, check func(n *yaml.Node) string) *field {
+	f.check = check
+	return f
+}
+
+// resolve follows YAML aliases to the node they point to
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// nodeKind maps a yaml node to the kind used by the schema
+func nodeKind(n *yaml.Node) kind {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return kindScalar
+	case yaml.MappingNode:
+		return kindMapping
+	case yaml.SequenceNode:
+		return kindSequence
+	}
+	return kindAny
+}
+
+// isNull reports whether a node is an explicit YAML null
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+// walk validates a node against a field description.
+// path is a human-readable location such as "api.endpoints[0].method".
+func walk(rep *reporter, n *yaml.Node, f *field, path string) {
+	n = resolve(n)
+	if n == nil || f.kind == kindAny {
+		return
+	}
+
+	if isNull(n) {
+		if f.required {
+			rep.errorf(n, "%s must not be empty", path)
+		}
+		return
+	}
+
+	if got := nodeKind(n); got != f.kind {
+		rep.errorf(n, "%s must be a %s, got %s", path, f.kind, got)
+		return
+	}
+
+	switch f.kind {
+	case kindScalar:
+		if f.required && n.Value == "" {
+			rep.errorf(n, "%s must not be empty", path)
+			return
+		}
+		if f.check != nil && n.Value != "" {
+			if msg := f.check(n); msg != "" {
+				rep.errorf(n, "%s: %s", path, msg)
+			}
+		}
+
+	case kindMapping:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			childPath := joinPath(path, key.Value)
+
+			if seen[key.Value] {
+				rep.errorf(key, "duplicate key %q in %s", key.Value, displayPath(path))
+				continue
+			}
+			seen[key.Value] = true
+
+			switch {
+			case f.fields != nil:
+				child, known := f.fields[key.Value]
+				if !known {
+					rep.errorf(key, "unknown key %q in %s", key.Value, displayPath(path))
+					continue
+				}
+				walk(rep, value, child, childPath)
+			case f.values != nil:
+				walk(rep, value, f.values, childPath)
+			}
+		}
+
+		for _, name := range sortedKeys(f.fields) {
+			if f.fields[name].required && !seen[name] {
+				rep.errorf(n, "missing required field %q in %s", name, displayPath(path))
+			}
+		}
+
+	case kindSequence:
+		if f.items == nil {
+			return
+		}
+		for i, item := range n.Content {
+			walk(rep, item, f.items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// lookup returns the value node for a key in a mapping node, or nil
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolve(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// sequenceItems returns the items of a sequence node, or nil
+func sequenceItems(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return n.Content
+}
+
+// sortedKeys returns the keys of a field map in sorted order
+func sortedKeys(fields map[string]*field) []string {
+	keys := make([]string, 0, len(fields))
+	for name := range fields {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "document root"
+	}
+	return path
+}
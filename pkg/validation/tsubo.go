@@ -0,0 +1,247 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/staka121/potter/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// tsuboSchema describes the structure of a .tsubo.yaml file
+func tsuboSchema() *field {
+	// port returns a new field each time since req marks fields in place
+	port := func() *field {
+		return withCheck(integer(), func(n *yaml.Node) string {
+			if p, err := strconv.Atoi(n.Value); err == nil && (p < 1 || p > 65535) {
+				return "port must be between 1 and 65535"
+			}
+			return ""
+		})
+	}
+
+	return mapping(map[string]*field{
+		"version": req(scalar()),
+		"tsubo": req(mapping(map[string]*field{
+			"name":        req(scalar()),
+			"description": scalar(),
+			"purpose":     scalar(),
+		})),
+		"objects": req(sequence(mapping(map[string]*field{
+			"name":        req(scalar()),
+			"description": scalar(),
+			"contract":    req(scalar()),
+			"runtime": req(mapping(map[string]*field{
+				"type":         scalar(),
+				"port":         req(port()),
+				"health_check": withCheck(scalar(), checkPath),
+			})),
+			"dependencies": sequence(scalar()),
+		}))),
+		"gateway": mapping(map[string]*field{
+			"enabled":  boolean(),
+			"name":     scalar(),
+			"port":     port(),
+			"contract": scalar(),
+			"routes": sequence(mapping(map[string]*field{
+				"service": req(scalar()),
+				"paths":   sequence(withCheck(scalar(), checkPath)),
+				"exclude": boolean(),
+			})),
+		}),
+		"deployment": mapping(map[string]*field{
+			"orchestration": scalar(),
+			"network": mapping(map[string]*field{
+				"name":   scalar(),
+				"driver": scalar(),
+			}),
+			"startup_order": sequence(scalar()),
+		}),
+		"integration_tests": sequence(mapping(map[string]*field{
+			"name":        req(scalar()),
+			"description": scalar(),
+		})),
+		"metadata": mapping(map[string]*field{
+			"version":    scalar(),
+			"created":    scalar(),
+			"philosophy": scalar(),
+			"labels":     dict(scalar()),
+		}),
+	})
+}
+
+// ValidateTsuboFile validates a .tsubo.yaml file and every object contract it references
+func ValidateTsuboFile(tsuboFile string) *Result {
+	result := &Result{}
+	rep := &reporter{file: tsuboFile, result: result}
+
+	root := loadDocument(tsuboFile, rep)
+	if root == nil {
+		return result
+	}
+
+	walk(rep, root, tsuboSchema(), "")
+
+	names := make(map[string]*yaml.Node)
+	ports := make(map[string]*yaml.Node)
+	contractsDir := filepath.Dir(tsuboFile)
+
+	for _, obj := range sequenceItems(lookup(root, "objects")) {
+		name := lookup(obj, "name")
+		if name != nil && name.Value != "" {
+			if first, exists := names[name.Value]; exists {
+				rep.errorf(name, "duplicate object name %q (first defined at line %d)", name.Value, first.Line)
+			} else {
+				names[name.Value] = name
+			}
+		}
+
+		if port := lookup(lookup(obj, "runtime"), "port"); port != nil && port.Value != "" {
+			if first, exists := ports[port.Value]; exists {
+				rep.errorf(port, "port %s is already used by the object at line %d", port.Value, first.Line)
+			} else {
+				ports[port.Value] = port
+			}
+		}
+
+		contract := lookup(obj, "contract")
+		if contract == nil || contract.Kind != yaml.ScalarNode || contract.Value == "" {
+			continue
+		}
+
+		contractPath := contract.Value
+		if !filepath.IsAbs(contractPath) {
+			contractPath = filepath.Join(contractsDir, contractPath)
+		}
+		if _, err := os.Stat(contractPath); err != nil {
+			rep.errorf(contract, "contract file not found: %s", contractPath)
+			continue
+		}
+
+		objResult := ValidateObjectFile(contractPath)
+		result.Merge(objResult)
+
+		if name != nil && name.Value != "" {
+			checkServiceName(rep, contractPath, name)
+		}
+	}
+
+	checkStartupOrder(rep, lookup(lookup(root, "deployment"), "startup_order"), names)
+	checkGateway(rep, lookup(root, "gateway"), names, ports, contractsDir)
+
+	result.Sort()
+	return result
+}
+
+// checkStartupOrder verifies that deployment.startup_order lists each object at most once
+// and only refers to objects defined in the tsubo
+func checkStartupOrder(rep *reporter, order *yaml.Node, names map[string]*yaml.Node) {
+	seen := make(map[string]*yaml.Node)
+	for _, item := range sequenceItems(order) {
+		if item.Kind != yaml.ScalarNode || item.Value == "" {
+			continue
+		}
+		if first, exists := seen[item.Value]; exists {
+			rep.errorf(item, "deployment.startup_order lists %q twice (first at line %d)", item.Value, first.Line)
+			continue
+		}
+		seen[item.Value] = item
+		if _, ok := names[item.Value]; !ok {
+			rep.errorf(item, "deployment.startup_order refers to unknown object %q", item.Value)
+		}
+	}
+
+	if len(seen) == 0 {
+		return
+	}
+	for name, node := range names {
+		if _, ok := seen[name]; !ok {
+			rep.warnf(node, "object %q is not listed in deployment.startup_order and will be started last", name)
+		}
+	}
+}
+
+// checkGateway verifies that the gateway section does not clash with the objects
+// and that its routes and contract refer to things that exist
+func checkGateway(rep *reporter, gateway *yaml.Node, names, ports map[string]*yaml.Node, contractsDir string) {
+	if gateway == nil {
+		return
+	}
+
+	enabled := len(names) > 1
+	if flag := lookup(gateway, "enabled"); flag != nil && flag.ShortTag() == "!!bool" {
+		enabled = flag.Value == "true"
+	}
+
+	name := lookup(gateway, "name")
+	if name != nil && name.Value != "" {
+		if first, exists := names[name.Value]; exists {
+			rep.errorf(name, "gateway name %q is already used by the object at line %d", name.Value, first.Line)
+		}
+	}
+
+	if port := lookup(gateway, "port"); enabled && port != nil && port.Value != "" {
+		if first, exists := ports[port.Value]; exists {
+			rep.errorf(port, "gateway port %s is already used by the object at line %d", port.Value, first.Line)
+		}
+	}
+
+	seen := make(map[string]*yaml.Node)
+	for _, route := range sequenceItems(lookup(gateway, "routes")) {
+		service := lookup(route, "service")
+		if service == nil || service.Kind != yaml.ScalarNode || service.Value == "" {
+			continue
+		}
+		if first, exists := seen[service.Value]; exists {
+			rep.errorf(service, "gateway.routes lists %q twice (first at line %d)", service.Value, first.Line)
+			continue
+		}
+		seen[service.Value] = service
+		if _, ok := names[service.Value]; !ok {
+			rep.errorf(service, "gateway.routes refers to unknown object %q", service.Value)
+		}
+		if exclude := lookup(route, "exclude"); exclude != nil && exclude.Value == "true" && lookup(route, "paths") != nil {
+			rep.warnf(exclude, "gateway route for %q is excluded; its paths are ignored", service.Value)
+		}
+	}
+
+	contract := lookup(gateway, "contract")
+	if contract == nil || contract.Kind != yaml.ScalarNode || contract.Value == "" {
+		return
+	}
+
+	contractPath := contract.Value
+	if !filepath.IsAbs(contractPath) {
+		contractPath = filepath.Join(contractsDir, contractPath)
+	}
+	if _, err := os.Stat(contractPath); err != nil {
+		rep.errorf(contract, "gateway contract file not found: %s", contractPath)
+		return
+	}
+
+	rep.result.Merge(ValidateObjectFile(contractPath))
+
+	// The gateway contract describes the gateway object itself
+	if name == nil || name.Value == "" {
+		name = &yaml.Node{Kind: yaml.ScalarNode, Value: parser.DefaultGatewayName, Line: contract.Line, Column: contract.Column}
+	}
+	checkServiceName(rep, contractPath, name)
+}
+
+// checkServiceName verifies that an object's name matches service.name in its contract
+func checkServiceName(rep *reporter, contractPath string, name *yaml.Node) {
+	data, err := os.ReadFile(contractPath)
+	if err != nil {
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return
+	}
+
+	serviceName := lookup(lookup(doc.Content[0], "service"), "name")
+	if serviceName != nil && serviceName.Value != "" && serviceName.Value != name.Value {
+		rep.errorf(name, "object name %q does not match service.name %q in %s", name.Value, serviceName.Value, filepath.Base(contractPath))
+	}
+}
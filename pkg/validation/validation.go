@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity classifies a validation issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue represents a single problem found in a contract file
+type Issue struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue as file:line:column: severity: message
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", i.File, i.Line, i.Column, i.Severity, i.Message)
+}
+
+// Result collects all issues found during validation
+type Result struct {
+	Issues []Issue
+}
+
+// HasErrors returns true if at least one issue is an error
+func (r *Result) HasErrors() bool {
+	return r.ErrorCount() > 0
+}
+
+// ErrorCount returns the number of error-level issues
+func (r *Result) ErrorCount() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// WarningCount returns the number of warning-level issues
+func (r *Result) WarningCount() int {
+	return len(r.Issues) - r.ErrorCount()
+}
+
+// Merge appends all issues from another result
+func (r *Result) Merge(other *Result) {
+	if other == nil {
+		return
+	}
+	r.Issues = append(r.Issues, other.Issues...)
+}
+
+// Sort orders issues by file, line and column and drops duplicates.
+// Duplicates occur when several contracts import the same type library.
+func (r *Result) Sort() {
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		a, b := r.Issues[i], r.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	seen := make(map[Issue]bool, len(r.Issues))
+	unique := r.Issues[:0]
+	for _, issue := range r.Issues {
+		if seen[issue] {
+			continue
+		}
+		seen[issue] = true
+		unique = append(unique, issue)
+	}
+	r.Issues = unique
+}
+
+// reporter records issues for a single file
+type reporter struct {
+	file   string
+	result *Result
+}
+
+func (r *reporter) errorf(n *yaml.Node, format string, args ...interface{}) {
+	r.add(n, SeverityError, format, args...)
+}
+
+func (r *reporter) warnf(n *yaml.Node, format string, args ...interface{}) {
+	r.add(n, SeverityWarning, format, args...)
+}
+
+func (r *reporter) add(n *yaml.Node, severity Severity, format string, args ...interface{}) {
+	issue := Issue{
+		File:     r.file,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if n != nil {
+		issue.Line = n.Line
+		issue.Column = n.Column
+	}
+	r.result.Issues = append(r.result.Issues, issue)
+}
+
+// loadDocument reads a YAML file and returns its root mapping node.
+// Read and syntax errors are reported as issues and yield a nil node.
+func loadDocument(filePath string, rep *reporter) *yaml.Node {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		rep.errorf(nil, "failed to read file: %v", err)
+		return nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		rep.errorf(nil, "invalid YAML: %v", err)
+		return nil
+	}
+
+	if len(doc.Content) == 0 {
+		rep.errorf(nil, "file is empty")
+		return nil
+	}
+
+	return resolve(doc.Content[0])
+}